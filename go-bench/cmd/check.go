@@ -0,0 +1,12 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
+)
+
+func init() {
+	rootCmd.AddCommand(harness.NewCheckCommand())
+}
@@ -8,84 +8,192 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"sync"
+	"sync/atomic"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
 	"github.com/spf13/cobra"
 )
 
+// Throughput modes for the benchmark container
+const (
+	throughputModeManual     = "manual"
+	throughputModeAutoscale  = "autoscale"
+	throughputModeServerless = "serverless"
+)
+
 // createDbCmd represents the createDb command
 var createDbCmd = &cobra.Command{
 	Use:   "createDb",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Short: "Create the benchmark database and container and seed it with sample documents",
+	Long: `Creates the benchmarking database and container, then inserts sample documents named
+item0..itemN-1 spread across partition0..partitionP-1, matching the keys used by pointRead.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+The command is idempotent: an existing database or container is reused and items that
+already exist are skipped, so it can be re-run to top up a partially seeded container.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client, err := createCosmosClient(cmd)
-		if err != nil {
-			fmt.Println("Error creating Cosmos client:", err)
-			return
-		}
-		dbClient, err := createTestDbClient(cmd, client)
+		err := runCreateDb(cmd)
 		if err != nil {
 			fmt.Println("Error creating database:", err)
 			return
 		}
+	},
+}
 
-		containerProperties := azcosmos.ContainerProperties{
-			ID: "RandomDocs",
-			PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{
-				Paths: []string{"/partitionKey"},
-				Kind:  azcosmos.PartitionKeyKindHash,
-			},
-		}
-		throughputProperties := azcosmos.NewManualThroughputProperties(40000)
-		_, err = dbClient.CreateContainer(cmd.Context(), containerProperties, &azcosmos.CreateContainerOptions{ThroughputProperties: &throughputProperties})
-		if err != nil {
-			fmt.Println("Error creating container:", err)
-			return
-		}
-		containerClient, err := dbClient.NewContainer("RandomDocs")
-		if err != nil {
-			fmt.Println("Error getting container client:", err)
-			return
-		}
-		fmt.Println("Database and container created successfully.")
+type createDbOptions struct {
+	itemCount       int
+	partitionCount  int
+	containerName   string
+	documentSize    int
+	documentSizeMax int
+	throughputMode  string
+	throughput      int32
+	concurrency     int
+}
 
-		const itemCount = 10000
-		const partitionCount = 10
+func getCreateDbOptions(cmd *cobra.Command) (*createDbOptions, error) {
+	itemCount, err := cmd.Flags().GetInt("item-count")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item-count: %w", err)
+	}
 
-		fmt.Printf("Inserting %d sample documents across %d partitions...\n", itemCount, partitionCount)
-		err = insertSampleDocuments(cmd, containerClient, itemCount, partitionCount)
-		if err != nil {
-			fmt.Println("Error inserting sample documents:", err)
-			return
-		}
-	},
+	partitionCount, err := cmd.Flags().GetInt("partition-count")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition-count: %w", err)
+	}
+
+	containerName, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container: %w", err)
+	}
+
+	documentSize, err := cmd.Flags().GetInt("document-size")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document-size: %w", err)
+	}
+
+	documentSizeMax, err := cmd.Flags().GetInt("document-size-max")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document-size-max: %w", err)
+	}
+
+	throughputMode, err := cmd.Flags().GetString("throughput-mode")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get throughput-mode: %w", err)
+	}
+
+	throughput, err := cmd.Flags().GetInt32("throughput")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get throughput: %w", err)
+	}
+
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concurrency: %w", err)
+	}
+
+	if itemCount <= 0 || partitionCount <= 0 || concurrency <= 0 || documentSize < 0 {
+		return nil, fmt.Errorf("item-count, partition-count and concurrency must be positive and document-size must not be negative")
+	}
+	if documentSizeMax != 0 && documentSizeMax < documentSize {
+		return nil, fmt.Errorf("document-size-max (%d) must not be less than document-size (%d)", documentSizeMax, documentSize)
+	}
+	switch throughputMode {
+	case throughputModeManual, throughputModeAutoscale, throughputModeServerless:
+	default:
+		return nil, fmt.Errorf("invalid throughput-mode %q, expected one of: %s, %s, %s", throughputMode, throughputModeManual, throughputModeAutoscale, throughputModeServerless)
+	}
+
+	return &createDbOptions{
+		itemCount:       itemCount,
+		partitionCount:  partitionCount,
+		containerName:   containerName,
+		documentSize:    documentSize,
+		documentSizeMax: documentSizeMax,
+		throughputMode:  throughputMode,
+		throughput:      throughput,
+		concurrency:     concurrency,
+	}, nil
+}
+
+func runCreateDb(cmd *cobra.Command) error {
+	opts, err := getCreateDbOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := createCosmosClient(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create Cosmos client: %w", err)
+	}
+
+	dbClient, err := createTestDbClient(cmd, client)
+	if isConflict(err) {
+		fmt.Println("Database already exists, reusing it.")
+		dbClient, err = getTestDbClient(cmd, client)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	containerProperties := azcosmos.ContainerProperties{
+		ID: opts.containerName,
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{
+			Paths: []string{"/partitionKey"},
+			Kind:  azcosmos.PartitionKeyKindHash,
+		},
+	}
+	var containerOptions azcosmos.CreateContainerOptions
+	switch opts.throughputMode {
+	case throughputModeManual:
+		throughputProperties := azcosmos.NewManualThroughputProperties(opts.throughput)
+		containerOptions.ThroughputProperties = &throughputProperties
+	case throughputModeAutoscale:
+		throughputProperties := azcosmos.NewAutoscaleThroughputProperties(opts.throughput)
+		containerOptions.ThroughputProperties = &throughputProperties
+	}
+	_, err = dbClient.CreateContainer(cmd.Context(), containerProperties, &containerOptions)
+	if isConflict(err) {
+		fmt.Printf("Container %s already exists, reusing it.\n", opts.containerName)
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+	containerClient, err := dbClient.NewContainer(opts.containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container client: %w", err)
+	}
+	fmt.Println("Database and container created successfully.")
+
+	fmt.Printf("Inserting %d sample documents across %d partitions...\n", opts.itemCount, opts.partitionCount)
+	return insertSampleDocuments(cmd, containerClient, opts)
+}
+
+// isConflict reports whether err is a 409 Conflict from the service, meaning the resource already exists
+func isConflict(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusConflict
 }
 
-func insertSampleDocuments(cmd *cobra.Command, dbClient *azcosmos.ContainerClient, itemCount, partitionCount int) error {
+func insertSampleDocuments(cmd *cobra.Command, dbClient *azcosmos.ContainerClient, opts *createDbOptions) error {
 	// Insert concurrently using goroutines
-	concurrency := 32
-	jobs := make(chan int, itemCount)
-	results := make(chan error, itemCount)
+	jobs := make(chan int, opts.itemCount)
+	results := make(chan error, opts.itemCount)
+	var inserted, skipped int64
 
 	var wg sync.WaitGroup
-	fmt.Printf("Starting insertion with %d concurrent workers...\n", concurrency)
-	for w := 0; w < concurrency; w++ {
+	fmt.Printf("Starting insertion with %d concurrent workers...\n", opts.concurrency)
+	for w := 0; w < opts.concurrency; w++ {
 		wg.Add(1)
-		go func() {
-			fmt.Printf("Worker %d started\n", w)
+		go func(workerID int) {
 			defer wg.Done()
+			localRand := rand.New(rand.NewSource(int64(workerID)))
 			for j := range jobs {
-				if j%1000 == 0 {
-					fmt.Printf("Worker %d inserting item %d...\n", w, j)
-				}
-				item := createRandomDocsItem(j, partitionCount)
+				item := createRandomDocsItem(localRand, j, opts.partitionCount, documentSizeFor(localRand, opts))
 				itemBytes, err := json.Marshal(item)
 				if err != nil {
 					results <- err
@@ -93,13 +201,23 @@ func insertSampleDocuments(cmd *cobra.Command, dbClient *azcosmos.ContainerClien
 				}
 				pk := azcosmos.NewPartitionKeyString(item.PartitionKey)
 				_, err = dbClient.CreateItem(cmd.Context(), pk, itemBytes, nil)
-				results <- err
+				if isConflict(err) {
+					// Already seeded by a previous run
+					atomic.AddInt64(&skipped, 1)
+					continue
+				}
+				if err != nil {
+					results <- err
+					continue
+				}
+				if n := atomic.AddInt64(&inserted, 1); n%1000 == 0 {
+					fmt.Printf("Inserted %d items...\n", n)
+				}
 			}
-			fmt.Printf("Worker %d complete\n", w)
-		}()
+		}(w)
 	}
 
-	for i := 0; i < itemCount; i++ {
+	for i := 0; i < opts.itemCount; i++ {
 		jobs <- i
 	}
 	close(jobs)
@@ -108,7 +226,7 @@ func insertSampleDocuments(cmd *cobra.Command, dbClient *azcosmos.ContainerClien
 
 	wg.Wait()
 
-	fmt.Println("Insertions complete, collecting results...")
+	fmt.Printf("Insertions complete: %d inserted, %d already existed\n", inserted, skipped)
 
 	errs := []error{}
 	close(results)
@@ -125,6 +243,15 @@ func insertSampleDocuments(cmd *cobra.Command, dbClient *azcosmos.ContainerClien
 	return nil
 }
 
+// documentSizeFor picks the size of the next document's data, uniformly between
+// document-size and document-size-max when a maximum is set
+func documentSizeFor(localRand *rand.Rand, opts *createDbOptions) int {
+	if opts.documentSizeMax <= opts.documentSize {
+		return opts.documentSize
+	}
+	return opts.documentSize + localRand.Intn(opts.documentSizeMax-opts.documentSize+1)
+}
+
 type RandomDocsItem struct {
 	ID           string `json:"id"`
 	PartitionKey string `json:"partitionKey"`
@@ -132,28 +259,24 @@ type RandomDocsItem struct {
 	RandomNumber int    `json:"randomNumber"`
 }
 
-func createRandomDocsItem(index, partitionCount int) RandomDocsItem {
+func createRandomDocsItem(localRand *rand.Rand, index, partitionCount, documentSize int) RandomDocsItem {
 	return RandomDocsItem{
 		ID:           fmt.Sprintf("item%d", index),
 		PartitionKey: fmt.Sprintf("partition%d", index%partitionCount),
-		Data:         generateRandomString(1024), // 1KB of random data
-		RandomNumber: generateRandomInt(0, 10000),
+		Data:         harness.RandomString(localRand, documentSize),
+		RandomNumber: localRand.Intn(10001),
 	}
 }
 
-func generateRandomString(size int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, size)
-	for i := range b {
-		b[i] = letters[generateRandomInt(0, len(letters)-1)]
-	}
-	return string(b)
-}
-
-func generateRandomInt(min, max int) int {
-	return min + rand.Intn(max-min+1)
-}
-
 func init() {
 	rootCmd.AddCommand(createDbCmd)
+
+	createDbCmd.Flags().IntP("item-count", "i", 10000, "Total number of items to insert")
+	createDbCmd.Flags().IntP("partition-count", "p", 10, "Number of partitions to distribute the items across")
+	createDbCmd.Flags().StringP("container", "c", "RandomDocs", "Container name")
+	createDbCmd.Flags().Int("document-size", 1024, "Size in bytes of the random data in each document")
+	createDbCmd.Flags().Int("document-size-max", 0, "If set, document data sizes are drawn uniformly between document-size and this value")
+	createDbCmd.Flags().String("throughput-mode", throughputModeManual, "Container throughput mode: manual, autoscale or serverless")
+	createDbCmd.Flags().Int32("throughput", 40000, "Manual RU/s, or maximum RU/s for autoscale (ignored for serverless)")
+	createDbCmd.Flags().Int("concurrency", 32, "Number of concurrent inserts")
 }
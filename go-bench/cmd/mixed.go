@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
+	"github.com/spf13/cobra"
+)
+
+// mixedCmd represents the mixed command
+var mixedCmd = &cobra.Command{
+	Use:   "mixed",
+	Short: "Benchmark a mix of read, write and query operations against CosmosDB",
+	Long: `Performs a benchmark that executes a weighted mix of point reads, creates, upserts,
+replaces, deletes and single-item queries against a CosmosDB container, reporting
+latency statistics for each kind of operation.
+
+Reads, upserts, replaces and queries target the items seeded by createDb, and upserts and
+replaces keep each item's id and partition key, so the seeded items stay readable. Creates
+add new items with run-specific ids, and deletes only remove items created earlier in the
+same run.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runMixedBenchmark(cmd)
+		if err != nil {
+			fmt.Printf("Error running benchmark: %v\n", err)
+			return
+		}
+	},
+}
+
+// mixedItems performs the mixed benchmark's item operations with the Go SDK
+type mixedItems struct {
+	container *azcosmos.ContainerClient
+}
+
+func (c *mixedItems) ReadItem(ctx context.Context, item harness.ItemKey) error {
+	_, err := c.container.ReadItem(ctx, azcosmos.NewPartitionKeyString(item.PartitionKey), item.ID, nil)
+	return err
+}
+
+func (c *mixedItems) CreateItem(ctx context.Context, item harness.ItemKey, document []byte) error {
+	_, err := c.container.CreateItem(ctx, azcosmos.NewPartitionKeyString(item.PartitionKey), document, nil)
+	return err
+}
+
+func (c *mixedItems) UpsertItem(ctx context.Context, item harness.ItemKey, document []byte) error {
+	_, err := c.container.UpsertItem(ctx, azcosmos.NewPartitionKeyString(item.PartitionKey), document, nil)
+	return err
+}
+
+func (c *mixedItems) ReplaceItem(ctx context.Context, item harness.ItemKey, document []byte) error {
+	_, err := c.container.ReplaceItem(ctx, azcosmos.NewPartitionKeyString(item.PartitionKey), item.ID, document, nil)
+	return err
+}
+
+func (c *mixedItems) DeleteItem(ctx context.Context, item harness.ItemKey) error {
+	_, err := c.container.DeleteItem(ctx, azcosmos.NewPartitionKeyString(item.PartitionKey), item.ID, nil)
+	return err
+}
+
+func (c *mixedItems) QueryItem(ctx context.Context, item harness.ItemKey) error {
+	pager := c.container.NewQueryItemsPager(harness.ItemQueryText, azcosmos.NewPartitionKeyString(item.PartitionKey), &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@id", Value: item.ID}},
+	})
+	for pager.More() {
+		if _, err := pager.NextPage(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mixedWorkload adds the SDK's error classification to the item mix
+type mixedWorkload struct {
+	*harness.ItemMix
+}
+
+func (w *mixedWorkload) ClassifyError(err error) string {
+	return classifyError(err)
+}
+
+func runMixedBenchmark(cmd *cobra.Command) error {
+	// Get configuration
+	cfg, err := harness.ConfigFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	containerName, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return fmt.Errorf("failed to get container: %w", err)
+	}
+
+	spec, err := cmd.Flags().GetString("mix")
+	if err != nil {
+		return fmt.Errorf("failed to get mix: %w", err)
+	}
+	ops, err := harness.ParseItemMix(spec)
+	if err != nil {
+		return err
+	}
+
+	documentSize, err := cmd.Flags().GetInt("document-size")
+	if err != nil {
+		return fmt.Errorf("failed to get document-size: %w", err)
+	}
+	if documentSize < 0 {
+		return fmt.Errorf("document-size must not be negative")
+	}
+
+	// Create Cosmos client and get container
+	client, err := createCosmosClient(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create Cosmos client: %w", err)
+	}
+
+	dbClient, err := getTestDbClient(cmd, client)
+	if err != nil {
+		return fmt.Errorf("failed to get database client: %w", err)
+	}
+
+	containerClient, err := dbClient.NewContainer(containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container client: %w", err)
+	}
+
+	fmt.Printf("Starting mixed benchmark...\n")
+	cfg.Print()
+	fmt.Printf("Container: %s\n", containerName)
+	fmt.Printf("Mix: %s\n", harness.FormatMix(ops))
+	fmt.Println()
+
+	workload := &mixedWorkload{harness.NewItemMix(&mixedItems{container: containerClient}, cfg, ops, documentSize)}
+
+	// Run benchmark
+	results, err := harness.Run(cmd.Context(), cfg, workload)
+	if err != nil {
+		return fmt.Errorf("benchmark failed: %w", err)
+	}
+
+	// Print results
+	results.Print()
+	return harness.WriteReport(cfg.Output, harness.NewReport(cmd, implementationName, workload.Name(), results))
+}
+
+func init() {
+	rootCmd.AddCommand(mixedCmd)
+
+	// Add benchmark-specific flags
+	harness.AddFlags(mixedCmd)
+	mixedCmd.Flags().StringP("container", "c", "RandomDocs", "Container name")
+	mixedCmd.Flags().String("mix", harness.DefaultItemMix, "Relative weights of each operation: read, create, upsert, replace, delete and query")
+	mixedCmd.Flags().Int("document-size", 1024, "Size in bytes of the random data in written documents")
+}
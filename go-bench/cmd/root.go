@@ -4,10 +4,14 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"os"
+	"strconv"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
 	"github.com/spf13/cobra"
 )
 
@@ -27,6 +31,9 @@ func Execute() {
 	}
 }
 
+// implementationName identifies this implementation in benchmark results
+const implementationName = "Go"
+
 // Well-known Cosmos DB Emulator key, not a secret.
 const emulatorKey = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
 
@@ -88,3 +95,16 @@ func createCosmosClient(cmd *cobra.Command) (*azcosmos.Client, error) {
 		return azcosmos.NewClient(endpoint, cred, nil)
 	}
 }
+
+// classifyError groups benchmark failures by Cosmos DB status code and sub-status
+func classifyError(err error) string {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return "error"
+	}
+	subStatus := 0
+	if respErr.RawResponse != nil {
+		subStatus, _ = strconv.Atoi(respErr.RawResponse.Header.Get("x-ms-substatus"))
+	}
+	return harness.StatusClass(respErr.StatusCode, subStatus)
+}
@@ -0,0 +1,88 @@
+package fakecosmos
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// EmulatorKey is the well-known Cosmos DB Emulator key, not a secret. The benchmark CLIs use it by default.
+const EmulatorKey = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
+
+// resourceFor returns the resource type and resource link a request is signed with.
+// Even-length paths address a single resource (dbs/db/colls/c), whose type is the second to last
+// segment; odd-length paths address a feed (dbs/db/colls), whose link is its parent.
+func resourceFor(segments []string) (resourceType, resourceLink string) {
+	if len(segments) == 0 {
+		return "", ""
+	}
+	if len(segments)%2 == 0 {
+		return segments[len(segments)-2], strings.Join(segments, "/")
+	}
+	return segments[len(segments)-1], strings.Join(segments[:len(segments)-1], "/")
+}
+
+// Signature computes the master key signature for a request, as described in
+// https://learn.microsoft.com/rest/api/cosmos-db/access-control-on-cosmosdb-resources
+func Signature(key []byte, verb, resourceType, resourceLink, date string) string {
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n",
+		strings.ToLower(verb),
+		strings.ToLower(resourceType),
+		resourceLink,
+		strings.ToLower(date),
+		"")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// AuthorizationHeader builds the Authorization header value a client would send for a request
+func AuthorizationHeader(key []byte, verb, resourceType, resourceLink, date string) string {
+	return url.QueryEscape("type=master&ver=1.0&sig=" + Signature(key, verb, resourceType, resourceLink, date))
+}
+
+// authorize checks the request's master key signature
+func (s *Server) authorize(r *http.Request, segments []string) error {
+	if s.key == nil {
+		return nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return fmt.Errorf("required Authorization header is missing")
+	}
+
+	decoded, err := url.QueryUnescape(header)
+	if err != nil {
+		return fmt.Errorf("malformed Authorization header: %w", err)
+	}
+
+	// The signature is base64, so the decoded token can't go through url.ParseQuery without
+	// mangling '+' characters
+	values := map[string]string{}
+	for _, field := range strings.Split(decoded, "&") {
+		name, value, _ := strings.Cut(field, "=")
+		values[name] = value
+	}
+	if values["type"] != "master" {
+		return fmt.Errorf("unsupported authorization type %q, only master keys are supported", values["type"])
+	}
+
+	date := r.Header.Get("x-ms-date")
+	if date == "" {
+		date = r.Header.Get("Date")
+	}
+
+	resourceType, resourceLink := resourceFor(segments)
+	expected := Signature(s.key, r.Method, resourceType, resourceLink, date)
+	if !hmac.Equal([]byte(values["sig"]), []byte(expected)) {
+		return fmt.Errorf("the input authorization token can't serve the request, check that the key is correct")
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+// Command fakecosmos serves an in-memory fake of the Cosmos DB REST API for offline benchmarking.
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/analogrelay/go-rust-interop/go-fakecosmos"
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "fakecosmos",
+	Short: "In-memory fake Cosmos DB server",
+	Long: `Serves an in-memory fake of the Cosmos DB REST API, covering the database, container,
+item and query operations used by the benchmarks. Requests are authenticated with the
+account key (the emulator key by default), and latency and errors can be injected into
+item operations to simulate a remote service.
+
+Data is not persisted: every run starts with an empty account.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServer(cmd)
+	},
+}
+
+func init() {
+	rootCmd.Flags().StringP("address", "a", "localhost:8081", "Address to listen on")
+	rootCmd.Flags().StringP("key", "k", fakecosmos.EmulatorKey, "Account key requests must be signed with (empty to disable authentication)")
+	rootCmd.Flags().Duration("latency", 0, "Latency added to every item operation")
+	rootCmd.Flags().Duration("latency-jitter", 0, "Maximum random latency added on top of --latency")
+	rootCmd.Flags().Float64("error-rate", 0, "Fraction of item operations that fail with 503 Service Unavailable")
+	rootCmd.Flags().Float64("throttle-rate", 0, "Fraction of item operations that fail with 429 Too Many Requests")
+	rootCmd.Flags().String("tls-cert", "", "TLS certificate file, serves HTTPS when set with --tls-key")
+	rootCmd.Flags().String("tls-key", "", "TLS private key file")
+}
+
+func runServer(cmd *cobra.Command) error {
+	address, err := cmd.Flags().GetString("address")
+	if err != nil {
+		return fmt.Errorf("failed to get address: %w", err)
+	}
+	key, err := cmd.Flags().GetString("key")
+	if err != nil {
+		return fmt.Errorf("failed to get key: %w", err)
+	}
+	latency, err := cmd.Flags().GetDuration("latency")
+	if err != nil {
+		return fmt.Errorf("failed to get latency: %w", err)
+	}
+	latencyJitter, err := cmd.Flags().GetDuration("latency-jitter")
+	if err != nil {
+		return fmt.Errorf("failed to get latency-jitter: %w", err)
+	}
+	errorRate, err := cmd.Flags().GetFloat64("error-rate")
+	if err != nil {
+		return fmt.Errorf("failed to get error-rate: %w", err)
+	}
+	throttleRate, err := cmd.Flags().GetFloat64("throttle-rate")
+	if err != nil {
+		return fmt.Errorf("failed to get throttle-rate: %w", err)
+	}
+	tlsCert, err := cmd.Flags().GetString("tls-cert")
+	if err != nil {
+		return fmt.Errorf("failed to get tls-cert: %w", err)
+	}
+	tlsKey, err := cmd.Flags().GetString("tls-key")
+	if err != nil {
+		return fmt.Errorf("failed to get tls-key: %w", err)
+	}
+
+	if (tlsCert == "") != (tlsKey == "") {
+		return fmt.Errorf("tls-cert and tls-key must be specified together")
+	}
+
+	server, err := fakecosmos.NewServer(fakecosmos.Options{
+		Key:           key,
+		Latency:       latency,
+		LatencyJitter: latencyJitter,
+		ErrorRate:     errorRate,
+		ThrottleRate:  throttleRate,
+	})
+	if err != nil {
+		return err
+	}
+
+	if tlsCert != "" {
+		fmt.Printf("Fake Cosmos DB listening on https://%s\n", address)
+		return http.ListenAndServeTLS(address, tlsCert, tlsKey, server)
+	}
+	fmt.Printf("Fake Cosmos DB listening on http://%s\n", address)
+	return http.ListenAndServe(address, server)
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,409 @@
+package fakecosmos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// query is a parsed query in the small SQL subset the fake server supports:
+//
+//	SELECT [TOP n] * FROM c [WHERE <condition>]
+//
+// where conditions compare document properties (c.a.b) with literals or @parameters using
+// =, !=, <>, <, <=, > and >=, combined with AND, OR and parentheses.
+type query struct {
+	top   int // 0 means no limit
+	where condition
+}
+
+type condition interface {
+	matches(doc map[string]any, params map[string]any) (bool, error)
+}
+
+type andCondition struct{ left, right condition }
+type orCondition struct{ left, right condition }
+
+type comparison struct {
+	left, right operand
+	op          string
+}
+
+// operand is a property path, a parameter name or a literal value
+type operand struct {
+	path  []string
+	param string
+	value any
+}
+
+func (c andCondition) matches(doc map[string]any, params map[string]any) (bool, error) {
+	ok, err := c.left.matches(doc, params)
+	if err != nil || !ok {
+		return false, err
+	}
+	return c.right.matches(doc, params)
+}
+
+func (c orCondition) matches(doc map[string]any, params map[string]any) (bool, error) {
+	ok, err := c.left.matches(doc, params)
+	if err != nil || ok {
+		return ok, err
+	}
+	return c.right.matches(doc, params)
+}
+
+func (c comparison) matches(doc map[string]any, params map[string]any) (bool, error) {
+	left, err := c.left.resolve(doc, params)
+	if err != nil {
+		return false, err
+	}
+	right, err := c.right.resolve(doc, params)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.op {
+	case "=":
+		return equalValues(left, right), nil
+	case "!=", "<>":
+		return !equalValues(left, right), nil
+	}
+
+	cmp, ok := compareValues(left, right)
+	if !ok {
+		// Like Cosmos DB, ordering comparisons between mismatched types are undefined and never match
+		return false, nil
+	}
+	switch c.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return false, fmt.Errorf("unsupported operator %q", c.op)
+}
+
+func (o operand) resolve(doc map[string]any, params map[string]any) (any, error) {
+	switch {
+	case o.path != nil:
+		value, _ := lookupPath(doc, o.path)
+		return value, nil
+	case o.param != "":
+		value, ok := params[o.param]
+		if !ok {
+			return nil, fmt.Errorf("parameter %s is not defined", o.param)
+		}
+		return value, nil
+	}
+	return o.value, nil
+}
+
+// lookupPath returns the value at a property path within a document
+func lookupPath(doc map[string]any, path []string) (any, bool) {
+	var current any = doc
+	for _, name := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// toFloat converts JSON numbers, whichever way they were decoded, to float64
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func equalValues(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	switch a := a.(type) {
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case nil:
+		return b == nil
+	}
+	return false
+}
+
+func compareValues(a, b any) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return strings.Compare(sa, sb), ok
+	}
+	return 0, false
+}
+
+// parseQuery parses the supported SQL subset
+func parseQuery(text string) (*query, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+
+	q := &query{}
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	if p.acceptKeyword("TOP") {
+		top, err := strconv.Atoi(p.next())
+		if err != nil || top <= 0 {
+			return nil, fmt.Errorf("TOP must be followed by a positive integer")
+		}
+		q.top = top
+	}
+	if p.next() != "*" {
+		return nil, fmt.Errorf("only SELECT * queries are supported")
+	}
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	p.alias = p.next()
+	if !isIdentifier(p.alias) {
+		return nil, fmt.Errorf("expected a collection alias after FROM")
+	}
+
+	if p.acceptKeyword("WHERE") {
+		q.where, err = p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	return q, nil
+}
+
+type queryParser struct {
+	tokens []string
+	pos    int
+	alias  string
+}
+
+func (p *queryParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *queryParser) acceptKeyword(keyword string) bool {
+	if strings.EqualFold(p.peek(), keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return fmt.Errorf("expected %s", keyword)
+	}
+	return nil
+}
+
+func (p *queryParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (condition, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseComparison() (condition, error) {
+	if p.peek() == "(" {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("expected )")
+		}
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	switch op {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("expected a comparison operator, got %q", op)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{left: left, right: right, op: op}, nil
+}
+
+func (p *queryParser) parseOperand() (operand, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return operand{}, fmt.Errorf("unexpected end of query")
+	case strings.HasPrefix(token, "@"):
+		return operand{param: token}, nil
+	case strings.HasPrefix(token, "'") || strings.HasPrefix(token, "\""):
+		return operand{value: token[1 : len(token)-1]}, nil
+	case strings.EqualFold(token, "true"):
+		return operand{value: true}, nil
+	case strings.EqualFold(token, "false"):
+		return operand{value: false}, nil
+	case strings.EqualFold(token, "null"):
+		return operand{value: nil}, nil
+	case token == p.alias:
+		var path []string
+		for p.peek() == "." {
+			p.pos++
+			name := p.next()
+			if !isIdentifier(name) {
+				return operand{}, fmt.Errorf("expected a property name after %s.", p.alias)
+			}
+			path = append(path, name)
+		}
+		if path == nil {
+			return operand{}, fmt.Errorf("comparing whole documents is not supported")
+		}
+		return operand{path: path}, nil
+	}
+
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return operand{value: f}, nil
+	}
+	return operand{}, fmt.Errorf("unexpected %q", token)
+}
+
+func isIdentifier(token string) bool {
+	if token == "" {
+		return false
+	}
+	for i, r := range token {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize splits a query into identifiers, literals, parameters and operators
+func tokenize(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, text[i:i+end+2])
+			i += end + 2
+		case strings.ContainsRune("*.,()", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case strings.ContainsRune("=!<>", rune(c)):
+			j := i + 1
+			for j < len(text) && strings.ContainsRune("=<>", rune(text[j])) {
+				j++
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(text) && (text[j] == '@' || text[j] == '_' || text[j] == '-' && j == i || isAlphaNumeric(text[j]) || text[j] == '.' && j > i && isDigit(text[i])) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func isAlphaNumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,575 @@
+// Package fakecosmos implements an in-memory fake of the Cosmos DB REST API, covering the
+// database, container, item and query operations the benchmarks use. It validates master key
+// signatures and can inject latency and errors, so benchmarks and tests can run end-to-end with
+// no emulator or network.
+package fakecosmos
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request charges reported for each kind of operation. They only need to be plausible.
+const (
+	readCharge  = 1.0
+	writeCharge = 5.0
+	queryCharge = 2.5
+)
+
+// throttledSubStatus is the sub-status Cosmos DB returns for requests rate limited by provisioned throughput
+const throttledSubStatus = 3200
+
+// defaultMaxItemCount is the page size used for queries that don't set x-ms-max-item-count
+const defaultMaxItemCount = 100
+
+// Options configures a fake server
+type Options struct {
+	// Key is the base64 account key requests must be signed with. If empty, requests are not authenticated.
+	Key string
+
+	// Latency is added to every item operation, plus a random amount up to LatencyJitter
+	Latency       time.Duration
+	LatencyJitter time.Duration
+
+	// ErrorRate is the fraction of item operations that fail with 503 Service Unavailable, and
+	// ThrottleRate the fraction that fail with 429 Too Many Requests
+	ErrorRate    float64
+	ThrottleRate float64
+
+	// RetryAfter is the delay suggested to throttled clients, 100ms if zero
+	RetryAfter time.Duration
+}
+
+// Server is an http.Handler serving the fake Cosmos DB API
+type Server struct {
+	key   []byte
+	opts  Options
+	store *store
+}
+
+// NewServer creates a fake server with empty storage
+func NewServer(opts Options) (*Server, error) {
+	if opts.ErrorRate < 0 || opts.ThrottleRate < 0 || opts.ErrorRate+opts.ThrottleRate > 1 {
+		return nil, fmt.Errorf("error rate and throttle rate must be non-negative and add up to at most 1")
+	}
+
+	s := &Server{opts: opts, store: newStore()}
+	if opts.Key != "" {
+		key, err := base64.StdEncoding.DecodeString(opts.Key)
+		if err != nil {
+			return nil, fmt.Errorf("account key must be base64: %w", err)
+		}
+		s.key = key
+	}
+	if s.opts.RetryAfter == 0 {
+		s.opts.RetryAfter = 100 * time.Millisecond
+	}
+	return s, nil
+}
+
+// ServeHTTP routes a request to the handler for the resource it addresses
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("x-ms-activity-id", newActivityID())
+
+	segments := splitPath(r.URL.Path)
+	if err := s.authorize(r, segments); err != nil {
+		writeError(w, newStatusError(http.StatusUnauthorized, "%v", err))
+		return
+	}
+
+	var err error
+	switch len(segments) {
+	case 0:
+		err = s.handleAccount(w, r)
+	case 1:
+		err = s.handleDatabases(w, r, segments)
+	case 2:
+		err = s.handleDatabase(w, r, segments)
+	case 3:
+		err = s.handleContainers(w, r, segments)
+	case 4:
+		err = s.handleContainer(w, r, segments)
+	case 5:
+		err = s.handleContainerFeed(w, r, segments)
+	case 6:
+		err = s.handleItem(w, r, segments)
+	default:
+		err = notFound(r)
+	}
+
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+func notFound(r *http.Request) error {
+	return newStatusError(http.StatusNotFound, "%s %s is not supported by the fake server", r.Method, r.URL.Path)
+}
+
+func methodNotAllowed(r *http.Request) error {
+	return newStatusError(http.StatusMethodNotAllowed, "%s is not supported on %s", r.Method, r.URL.Path)
+}
+
+// checkSegments validates the fixed segments of a resource path, such as "dbs" and "colls"
+func checkSegments(r *http.Request, segments []string, names ...string) error {
+	for i, name := range names {
+		if segments[2*i] != name {
+			return notFound(r)
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return methodNotAllowed(r)
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	endpoint := fmt.Sprintf("%s://%s/", scheme, r.Host)
+	locations := []map[string]any{{"name": "Local", "databaseAccountEndpoint": endpoint}}
+
+	return writeJSON(w, http.StatusOK, 0, map[string]any{
+		"id":                           "fakecosmos",
+		"_rid":                         r.Host,
+		"_self":                        "",
+		"media":                        "//media/",
+		"addresses":                    "//addresses/",
+		"_dbs":                         "//dbs/",
+		"writableLocations":            locations,
+		"readableLocations":            locations,
+		"enableMultipleWriteLocations": false,
+		"userConsistencyPolicy":        map[string]any{"defaultConsistencyLevel": "Session"},
+		"systemReplicationPolicy":      map[string]any{"minReplicaSetSize": 1, "maxReplicasetSize": 1},
+		"userReplicationPolicy":        map[string]any{"minReplicaSetSize": 1, "maxReplicasetSize": 1},
+		"readPolicy":                   map[string]any{"primaryReadCoefficient": 1, "secondaryReadCoefficient": 1},
+		"queryEngineConfiguration":     "{}",
+	})
+}
+
+func (s *Server) handleDatabases(w http.ResponseWriter, r *http.Request, segments []string) error {
+	if err := checkSegments(r, segments, "dbs"); err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var props map[string]any
+		if err := readBody(r, &props); err != nil {
+			return err
+		}
+		db, err := s.store.createDatabase(props)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusCreated, writeCharge, db)
+	case http.MethodGet:
+		return writeFeed(w, "Databases", s.store.listDatabases())
+	}
+	return methodNotAllowed(r)
+}
+
+func (s *Server) handleDatabase(w http.ResponseWriter, r *http.Request, segments []string) error {
+	if err := checkSegments(r, segments, "dbs"); err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		db, err := s.store.readDatabase(segments[1])
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusOK, readCharge, db)
+	case http.MethodDelete:
+		if err := s.store.deleteDatabase(segments[1]); err != nil {
+			return err
+		}
+		return writeNoContent(w, writeCharge)
+	}
+	return methodNotAllowed(r)
+}
+
+func (s *Server) handleContainers(w http.ResponseWriter, r *http.Request, segments []string) error {
+	if err := checkSegments(r, segments, "dbs", "colls"); err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var props map[string]any
+		if err := readBody(r, &props); err != nil {
+			return err
+		}
+		c, err := s.store.createContainer(segments[1], props)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusCreated, writeCharge, c)
+	case http.MethodGet:
+		containers, err := s.store.listContainers(segments[1])
+		if err != nil {
+			return err
+		}
+		return writeFeed(w, "DocumentCollections", containers)
+	}
+	return methodNotAllowed(r)
+}
+
+func (s *Server) handleContainer(w http.ResponseWriter, r *http.Request, segments []string) error {
+	if err := checkSegments(r, segments, "dbs", "colls"); err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		c, err := s.store.readContainer(segments[1], segments[3])
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusOK, readCharge, c)
+	case http.MethodDelete:
+		if err := s.store.deleteContainer(segments[1], segments[3]); err != nil {
+			return err
+		}
+		return writeNoContent(w, writeCharge)
+	}
+	return methodNotAllowed(r)
+}
+
+func (s *Server) handleContainerFeed(w http.ResponseWriter, r *http.Request, segments []string) error {
+	if err := checkSegments(r, segments, "dbs", "colls"); err != nil {
+		return err
+	}
+	dbID, containerID := segments[1], segments[3]
+
+	switch segments[4] {
+	case "pkranges":
+		if r.Method != http.MethodGet {
+			return methodNotAllowed(r)
+		}
+		if _, err := s.store.readContainer(dbID, containerID); err != nil {
+			return err
+		}
+		// Everything lives in a single physical partition
+		return writeFeed(w, "PartitionKeyRanges", []map[string]any{{
+			"id":           "0",
+			"_rid":         "0",
+			"minInclusive": "",
+			"maxExclusive": "FF",
+			"parents":      []string{},
+			"status":       "online",
+		}})
+	case "docs":
+	default:
+		return notFound(r)
+	}
+
+	if err := s.injectFaults(r); err != nil {
+		return err
+	}
+
+	pk, err := headerPartitionKey(r)
+	if err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		if isQuery(r) {
+			return s.handleQuery(w, r, dbID, containerID, pk)
+		}
+
+		var item map[string]any
+		if err := readBody(r, &item); err != nil {
+			return err
+		}
+		mode := writeCreate
+		if strings.EqualFold(r.Header.Get("x-ms-documentdb-is-upsert"), "true") {
+			mode = writeUpsert
+		}
+		stored, created, err := s.store.putItem(dbID, containerID, pk, item, mode, r.Header.Get("If-Match"))
+		if err != nil {
+			return err
+		}
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		return writeItem(w, r, status, writeCharge, stored)
+	case http.MethodGet:
+		// A read feed is a query for every item
+		return s.runQuery(w, r, dbID, containerID, pk, &query{}, nil)
+	}
+	return methodNotAllowed(r)
+}
+
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request, segments []string) error {
+	if err := checkSegments(r, segments, "dbs", "colls", "docs"); err != nil {
+		return err
+	}
+	dbID, containerID, id := segments[1], segments[3], segments[5]
+
+	if err := s.injectFaults(r); err != nil {
+		return err
+	}
+
+	pk, err := headerPartitionKey(r)
+	if err != nil {
+		return err
+	}
+	if pk == "" {
+		return newStatusError(http.StatusBadRequest, "the x-ms-documentdb-partitionkey header is required for item operations")
+	}
+	ifMatch := r.Header.Get("If-Match")
+
+	switch r.Method {
+	case http.MethodGet:
+		item, err := s.store.readItem(dbID, containerID, pk, id)
+		if err != nil {
+			return err
+		}
+		if etag := r.Header.Get("If-None-Match"); etag != "" && etag == item["_etag"] {
+			w.Header().Set("etag", etag)
+			return writeNoBody(w, http.StatusNotModified, readCharge)
+		}
+		return writeItem(w, r, http.StatusOK, readCharge, item)
+	case http.MethodPut:
+		var item map[string]any
+		if err := readBody(r, &item); err != nil {
+			return err
+		}
+		if itemID, _ := item["id"].(string); itemID != id {
+			return newStatusError(http.StatusBadRequest, "the item's id %q does not match the request path", itemID)
+		}
+		stored, _, err := s.store.putItem(dbID, containerID, pk, item, writeReplace, ifMatch)
+		if err != nil {
+			return err
+		}
+		return writeItem(w, r, http.StatusOK, writeCharge, stored)
+	case http.MethodDelete:
+		if err := s.store.deleteItem(dbID, containerID, pk, id, ifMatch); err != nil {
+			return err
+		}
+		return writeNoContent(w, writeCharge)
+	case http.MethodPatch:
+		var body struct {
+			Operations []patchOperation `json:"operations"`
+		}
+		if err := readBody(r, &body); err != nil {
+			return err
+		}
+		stored, err := s.store.patchItem(dbID, containerID, pk, id, body.Operations, ifMatch)
+		if err != nil {
+			return err
+		}
+		return writeItem(w, r, http.StatusOK, writeCharge, stored)
+	}
+	return methodNotAllowed(r)
+}
+
+func isQuery(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("x-ms-documentdb-isquery"), "true") ||
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/query+json")
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request, dbID, containerID, pk string) error {
+	var body struct {
+		Query      string `json:"query"`
+		Parameters []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"parameters"`
+	}
+	if err := readBody(r, &body); err != nil {
+		return err
+	}
+
+	q, err := parseQuery(body.Query)
+	if err != nil {
+		return newStatusError(http.StatusBadRequest, "unsupported query %q: %v", body.Query, err)
+	}
+	params := make(map[string]any, len(body.Parameters))
+	for _, p := range body.Parameters {
+		params[p.Name] = p.Value
+	}
+
+	return s.runQuery(w, r, dbID, containerID, pk, q, params)
+}
+
+// runQuery writes one page of query results. The continuation token is the offset of the next page.
+func (s *Server) runQuery(w http.ResponseWriter, r *http.Request, dbID, containerID, pk string, q *query, params map[string]any) error {
+	items, err := s.store.queryItems(dbID, containerID, pk, q, params)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if continuation := r.Header.Get("x-ms-continuation"); continuation != "" {
+		offset, err = strconv.Atoi(continuation)
+		if err != nil || offset < 0 || offset > len(items) {
+			return newStatusError(http.StatusBadRequest, "invalid continuation token %q", continuation)
+		}
+	}
+
+	pageSize := defaultMaxItemCount
+	if maxItemCount := r.Header.Get("x-ms-max-item-count"); maxItemCount != "" {
+		n, err := strconv.Atoi(maxItemCount)
+		if err != nil {
+			return newStatusError(http.StatusBadRequest, "invalid x-ms-max-item-count %q", maxItemCount)
+		}
+		if n > 0 {
+			pageSize = n
+		}
+	}
+
+	end := min(offset+pageSize, len(items))
+	if end < len(items) {
+		w.Header().Set("x-ms-continuation", strconv.Itoa(end))
+	}
+	w.Header().Set("x-ms-request-charge", strconv.FormatFloat(queryCharge, 'f', -1, 64))
+	return writeFeed(w, "Documents", items[offset:end])
+}
+
+// injectFaults applies the configured latency, then fails a random fraction of requests
+func (s *Server) injectFaults(r *http.Request) error {
+	delay := s.opts.Latency
+	if s.opts.LatencyJitter > 0 {
+		delay += time.Duration(mathrand.Int64N(int64(s.opts.LatencyJitter)))
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+	}
+
+	roll := mathrand.Float64()
+	if roll < s.opts.ThrottleRate {
+		err := newStatusError(http.StatusTooManyRequests, "request rate is large, retry after %v", s.opts.RetryAfter)
+		err.SubStatus = throttledSubStatus
+		err.RetryAfter = s.opts.RetryAfter
+		return err
+	}
+	if roll < s.opts.ThrottleRate+s.opts.ErrorRate {
+		return newStatusError(http.StatusServiceUnavailable, "injected failure")
+	}
+	return nil
+}
+
+// headerPartitionKey returns the request's partition key header, normalized to match the
+// encoding the store uses. It returns "" if the header is not set.
+func headerPartitionKey(r *http.Request) (string, error) {
+	header := r.Header.Get("x-ms-documentdb-partitionkey")
+	if header == "" {
+		return "", nil
+	}
+	var values []any
+	if err := decodeJSON([]byte(header), &values); err != nil {
+		return "", newStatusError(http.StatusBadRequest, "invalid partition key %q: %v", header, err)
+	}
+	encoded, _ := json.Marshal(values)
+	return string(encoded), nil
+}
+
+// decodeJSON decodes JSON, keeping numbers as json.Number so they round-trip unchanged
+func decodeJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func readBody(r *http.Request, v any) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return newStatusError(http.StatusBadRequest, "failed to read request body: %v", err)
+	}
+	if err := decodeJSON(data, v); err != nil {
+		return newStatusError(http.StatusBadRequest, "invalid JSON request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, charge float64, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if w.Header().Get("x-ms-request-charge") == "" {
+		w.Header().Set("x-ms-request-charge", strconv.FormatFloat(charge, 'f', -1, 64))
+	}
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// writeItem writes an item, honoring Prefer: return=minimal, which asks for writes to return no body
+func writeItem(w http.ResponseWriter, r *http.Request, status int, charge float64, item map[string]any) error {
+	if etag, ok := item["_etag"].(string); ok {
+		w.Header().Set("etag", etag)
+	}
+	if r.Method != http.MethodGet && strings.Contains(r.Header.Get("Prefer"), "return=minimal") {
+		return writeNoBody(w, status, charge)
+	}
+	return writeJSON(w, status, charge, item)
+}
+
+func writeFeed(w http.ResponseWriter, name string, resources []map[string]any) error {
+	w.Header().Set("x-ms-item-count", strconv.Itoa(len(resources)))
+	return writeJSON(w, http.StatusOK, readCharge, map[string]any{
+		"_rid":   "",
+		name:     resources,
+		"_count": len(resources),
+	})
+}
+
+func writeNoContent(w http.ResponseWriter, charge float64) error {
+	return writeNoBody(w, http.StatusNoContent, charge)
+}
+
+func writeNoBody(w http.ResponseWriter, status int, charge float64) error {
+	w.Header().Set("x-ms-request-charge", strconv.FormatFloat(charge, 'f', -1, 64))
+	w.WriteHeader(status)
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	statusErr, ok := err.(*statusError)
+	if !ok {
+		statusErr = newStatusError(http.StatusInternalServerError, "%v", err)
+	}
+	if statusErr.SubStatus != 0 {
+		w.Header().Set("x-ms-substatus", strconv.Itoa(statusErr.SubStatus))
+	}
+	if statusErr.RetryAfter > 0 {
+		w.Header().Set("x-ms-retry-after-ms", strconv.FormatInt(statusErr.RetryAfter.Milliseconds(), 10))
+	}
+	writeJSON(w, statusErr.StatusCode, 0, map[string]string{
+		"code":    statusErr.Code,
+		"message": statusErr.Message,
+	})
+}
+
+// newActivityID generates a random UUID to identify a request
+func newActivityID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,201 @@
+package fakecosmos
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testClient struct {
+	t      *testing.T
+	server *httptest.Server
+	key    []byte
+}
+
+func newTestClient(t *testing.T, opts Options) *testClient {
+	server, err := NewServer(opts)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	key, _ := base64.StdEncoding.DecodeString(EmulatorKey)
+	return &testClient{t: t, server: ts, key: key}
+}
+
+// do sends a signed request and decodes the response body into out, if given
+func (c *testClient) do(method, path string, headers map[string]string, body any, out any) *http.Response {
+	c.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.server.URL+path, reader)
+	if err != nil {
+		c.t.Fatalf("NewRequest: %v", err)
+	}
+
+	date := time.Now().UTC().Format(http.TimeFormat)
+	resourceType, resourceLink := resourceFor(splitPath(path))
+	req.Header.Set("x-ms-date", date)
+	req.Header.Set("Authorization", AuthorizationHeader(c.key, method, resourceType, resourceLink, date))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			c.t.Fatalf("decoding %s %s response: %v", method, path, err)
+		}
+	}
+	return resp
+}
+
+func (c *testClient) expectStatus(resp *http.Response, status int) {
+	c.t.Helper()
+	if resp.StatusCode != status {
+		c.t.Fatalf("%s %s: expected status %d, got %d", resp.Request.Method, resp.Request.URL.Path, status, resp.StatusCode)
+	}
+}
+
+func (c *testClient) createContainer() {
+	c.t.Helper()
+	c.expectStatus(c.do("POST", "/dbs", nil, map[string]any{"id": "db"}, nil), http.StatusCreated)
+	c.expectStatus(c.do("POST", "/dbs/db/colls", nil, map[string]any{
+		"id":           "items",
+		"partitionKey": map[string]any{"paths": []string{"/pk"}, "kind": "Hash"},
+	}, nil), http.StatusCreated)
+}
+
+func TestNewServerRejectsInvalidRates(t *testing.T) {
+	for _, opts := range []Options{
+		{ErrorRate: -0.1},
+		{ThrottleRate: 1.5},
+		{ErrorRate: 0.6, ThrottleRate: 0.6},
+	} {
+		if _, err := NewServer(opts); err == nil {
+			t.Errorf("expected error rate %v and throttle rate %v to be rejected", opts.ErrorRate, opts.ThrottleRate)
+		}
+	}
+	if _, err := NewServer(Options{ErrorRate: 0.5, ThrottleRate: 0.5}); err != nil {
+		t.Errorf("expected rates adding up to 1 to be accepted: %v", err)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	c := newTestClient(t, Options{Key: EmulatorKey})
+	c.expectStatus(c.do("GET", "/dbs", nil, nil, nil), http.StatusOK)
+
+	c.key = []byte("wrong key")
+	c.expectStatus(c.do("GET", "/dbs", nil, nil, nil), http.StatusUnauthorized)
+}
+
+func TestItemOperations(t *testing.T) {
+	c := newTestClient(t, Options{Key: EmulatorKey})
+	c.createContainer()
+	pk := map[string]string{"x-ms-documentdb-partitionkey": `["p1"]`}
+
+	c.expectStatus(c.do("POST", "/dbs/db/colls/items/docs", pk, map[string]any{"id": "a", "pk": "p1", "n": 1}, nil), http.StatusCreated)
+	c.expectStatus(c.do("POST", "/dbs/db/colls/items/docs", pk, map[string]any{"id": "a", "pk": "p1"}, nil), http.StatusConflict)
+
+	var item map[string]any
+	resp := c.do("GET", "/dbs/db/colls/items/docs/a", pk, nil, &item)
+	c.expectStatus(resp, http.StatusOK)
+	if item["n"] != 1.0 || item["_etag"] == nil {
+		t.Fatalf("unexpected item %v", item)
+	}
+	etag := resp.Header.Get("etag")
+
+	c.expectStatus(c.do("GET", "/dbs/db/colls/items/docs/a", map[string]string{"x-ms-documentdb-partitionkey": `["p2"]`}, nil, nil), http.StatusNotFound)
+
+	c.expectStatus(c.do("PATCH", "/dbs/db/colls/items/docs/a", pk, map[string]any{
+		"operations": []map[string]any{{"op": "incr", "path": "/n", "value": 2}},
+	}, &item), http.StatusOK)
+	if item["n"] != 3.0 {
+		t.Fatalf("expected n to be incremented to 3, got %v", item["n"])
+	}
+
+	// The patch changed the etag, so a conditional replace with the original one must fail
+	conditional := map[string]string{"x-ms-documentdb-partitionkey": `["p1"]`, "If-Match": etag}
+	c.expectStatus(c.do("PUT", "/dbs/db/colls/items/docs/a", conditional, map[string]any{"id": "a", "pk": "p1"}, nil), http.StatusPreconditionFailed)
+
+	upsert := map[string]string{"x-ms-documentdb-partitionkey": `["p1"]`, "x-ms-documentdb-is-upsert": "true"}
+	c.expectStatus(c.do("POST", "/dbs/db/colls/items/docs", upsert, map[string]any{"id": "a", "pk": "p1"}, nil), http.StatusOK)
+
+	c.expectStatus(c.do("DELETE", "/dbs/db/colls/items/docs/a", pk, nil, nil), http.StatusNoContent)
+	c.expectStatus(c.do("DELETE", "/dbs/db/colls/items/docs/a", pk, nil, nil), http.StatusNotFound)
+}
+
+func TestQuery(t *testing.T) {
+	c := newTestClient(t, Options{Key: EmulatorKey})
+	c.createContainer()
+	for i, pk := range []string{"p1", "p1", "p2", "p1", "p2"} {
+		item := map[string]any{"id": string(rune('a' + i)), "pk": pk, "n": i}
+		c.expectStatus(c.do("POST", "/dbs/db/colls/items/docs", map[string]string{"x-ms-documentdb-partitionkey": `["` + pk + `"]`}, item, nil), http.StatusCreated)
+	}
+
+	query := map[string]any{
+		"query":      "SELECT * FROM c WHERE c.n >= @min AND (c.pk = 'p1' OR c.id = 'e')",
+		"parameters": []map[string]any{{"name": "@min", "value": 1}},
+	}
+
+	var ids []string
+	continuation := ""
+	for pages := 0; ; pages++ {
+		headers := map[string]string{
+			"x-ms-documentdb-isquery": "true",
+			"Content-Type":            "application/query+json",
+			"x-ms-max-item-count":     "2",
+		}
+		if continuation != "" {
+			headers["x-ms-continuation"] = continuation
+		}
+
+		var page struct {
+			Documents []map[string]any
+		}
+		resp := c.do("POST", "/dbs/db/colls/items/docs", headers, query, &page)
+		c.expectStatus(resp, http.StatusOK)
+		for _, doc := range page.Documents {
+			ids = append(ids, doc["id"].(string))
+		}
+
+		continuation = resp.Header.Get("x-ms-continuation")
+		if continuation == "" {
+			break
+		}
+		if pages > 5 {
+			t.Fatalf("query did not finish paging")
+		}
+	}
+
+	if got := strings.Join(ids, ","); got != "b,d,e" {
+		t.Fatalf("expected items b,d,e, got %s", got)
+	}
+}
+
+func TestInjectedThrottling(t *testing.T) {
+	c := newTestClient(t, Options{Key: EmulatorKey, ThrottleRate: 1, RetryAfter: 50 * time.Millisecond})
+	c.createContainer()
+
+	resp := c.do("GET", "/dbs/db/colls/items/docs/a", map[string]string{"x-ms-documentdb-partitionkey": `["p1"]`}, nil, nil)
+	c.expectStatus(resp, http.StatusTooManyRequests)
+	if resp.Header.Get("x-ms-retry-after-ms") != "50" || resp.Header.Get("x-ms-substatus") != "3200" {
+		t.Fatalf("unexpected throttling headers %v", resp.Header)
+	}
+}
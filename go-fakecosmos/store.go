@@ -0,0 +1,481 @@
+package fakecosmos
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statusError is an error that maps onto a Cosmos DB error response
+type statusError struct {
+	StatusCode int
+	SubStatus  int
+	Code       string
+	Message    string
+
+	// RetryAfter is sent as x-ms-retry-after-ms when set
+	RetryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+func newStatusError(statusCode int, format string, args ...any) *statusError {
+	return &statusError{
+		StatusCode: statusCode,
+		Code:       http.StatusText(statusCode),
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// store holds all databases, containers and items in memory
+type store struct {
+	mu        sync.RWMutex
+	databases map[string]*database
+	nextRID   atomic.Uint64
+}
+
+type database struct {
+	properties map[string]any
+	containers map[string]*container
+}
+
+type container struct {
+	properties        map[string]any
+	partitionKeyPaths [][]string
+
+	// items are keyed by the JSON encoding of their partition key value, then by id
+	items map[string]map[string]map[string]any
+}
+
+func newStore() *store {
+	return &store{databases: map[string]*database{}}
+}
+
+// stamp sets the system properties Cosmos DB adds to every resource
+func (s *store) stamp(resource map[string]any, rid string) {
+	if rid == "" {
+		rid = strconv.FormatUint(s.nextRID.Add(1), 36)
+	}
+	resource["_rid"] = rid
+	resource["_etag"] = fmt.Sprintf("\"%016x\"", s.nextRID.Add(1))
+	resource["_ts"] = time.Now().Unix()
+}
+
+// resourceID returns the "id" property of a resource body
+func resourceID(resource map[string]any) (string, error) {
+	id, ok := resource["id"].(string)
+	if !ok || id == "" {
+		return "", newStatusError(http.StatusBadRequest, "the resource must have a non-empty string 'id' property")
+	}
+	return id, nil
+}
+
+func (s *store) createDatabase(props map[string]any) (map[string]any, error) {
+	id, err := resourceID(props)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.databases[id]; ok {
+		return nil, newStatusError(http.StatusConflict, "database %s already exists", id)
+	}
+	s.stamp(props, "")
+	s.databases[id] = &database{properties: props, containers: map[string]*container{}}
+	return props, nil
+}
+
+func (s *store) listDatabases() []map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]map[string]any, 0, len(s.databases))
+	for _, db := range s.databases {
+		result = append(result, db.properties)
+	}
+	sortByID(result)
+	return result
+}
+
+func (s *store) readDatabase(dbID string) (map[string]any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	db, err := s.database(dbID)
+	if err != nil {
+		return nil, err
+	}
+	return db.properties, nil
+}
+
+func (s *store) deleteDatabase(dbID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.database(dbID); err != nil {
+		return err
+	}
+	delete(s.databases, dbID)
+	return nil
+}
+
+// database looks up a database, the caller must hold the lock
+func (s *store) database(dbID string) (*database, error) {
+	db, ok := s.databases[dbID]
+	if !ok {
+		return nil, newStatusError(http.StatusNotFound, "database %s does not exist", dbID)
+	}
+	return db, nil
+}
+
+// container looks up a container, the caller must hold the lock
+func (s *store) container(dbID, containerID string) (*container, error) {
+	db, err := s.database(dbID)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := db.containers[containerID]
+	if !ok {
+		return nil, newStatusError(http.StatusNotFound, "container %s does not exist in database %s", containerID, dbID)
+	}
+	return c, nil
+}
+
+func (s *store) createContainer(dbID string, props map[string]any) (map[string]any, error) {
+	id, err := resourceID(props)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths [][]string
+	if pk, ok := props["partitionKey"].(map[string]any); ok {
+		rawPaths, _ := pk["paths"].([]any)
+		for _, raw := range rawPaths {
+			path, ok := raw.(string)
+			if !ok {
+				return nil, newStatusError(http.StatusBadRequest, "partition key paths must be strings")
+			}
+			paths = append(paths, splitPath(path))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, newStatusError(http.StatusBadRequest, "the container must define a partition key")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := s.database(dbID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := db.containers[id]; ok {
+		return nil, newStatusError(http.StatusConflict, "container %s already exists in database %s", id, dbID)
+	}
+	s.stamp(props, "")
+	db.containers[id] = &container{
+		properties:        props,
+		partitionKeyPaths: paths,
+		items:             map[string]map[string]map[string]any{},
+	}
+	return props, nil
+}
+
+func (s *store) listContainers(dbID string) ([]map[string]any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	db, err := s.database(dbID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]any, 0, len(db.containers))
+	for _, c := range db.containers {
+		result = append(result, c.properties)
+	}
+	sortByID(result)
+	return result, nil
+}
+
+func (s *store) readContainer(dbID, containerID string) (map[string]any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, err := s.container(dbID, containerID)
+	if err != nil {
+		return nil, err
+	}
+	return c.properties, nil
+}
+
+func (s *store) deleteContainer(dbID, containerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := s.database(dbID)
+	if err != nil {
+		return err
+	}
+	if _, ok := db.containers[containerID]; !ok {
+		return newStatusError(http.StatusNotFound, "container %s does not exist in database %s", containerID, dbID)
+	}
+	delete(db.containers, containerID)
+	return nil
+}
+
+// partitionKeyOf extracts an item's partition key value and encodes it the way clients send it
+// in the x-ms-documentdb-partitionkey header
+func (c *container) partitionKeyOf(item map[string]any) string {
+	values := make([]any, 0, len(c.partitionKeyPaths))
+	for _, path := range c.partitionKeyPaths {
+		value, ok := lookupPath(item, path)
+		if !ok {
+			// Items without a partition key value are stored under the "undefined" key
+			value = map[string]any{}
+		}
+		values = append(values, value)
+	}
+	encoded, _ := json.Marshal(values)
+	return string(encoded)
+}
+
+// writeMode selects how putItem treats an existing item
+type writeMode int
+
+const (
+	writeCreate writeMode = iota
+	writeUpsert
+	writeReplace
+)
+
+// putItem stores an item. headerKey is the partition key from the request header, which must match
+// the item's own partition key when given. ifMatch is an optional etag precondition.
+func (s *store) putItem(dbID, containerID, headerKey string, item map[string]any, mode writeMode, ifMatch string) (map[string]any, bool, error) {
+	id, err := resourceID(item)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.container(dbID, containerID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pk := c.partitionKeyOf(item)
+	if headerKey != "" && headerKey != pk {
+		return nil, false, newStatusError(http.StatusBadRequest, "the partition key %s in the header does not match the item's partition key %s", headerKey, pk)
+	}
+
+	partition := c.items[pk]
+	existing, exists := partition[id]
+	switch {
+	case mode == writeCreate && exists:
+		return nil, false, newStatusError(http.StatusConflict, "an item with id %s already exists in partition %s", id, pk)
+	case mode == writeReplace && !exists:
+		return nil, false, newStatusError(http.StatusNotFound, "item %s does not exist in partition %s", id, pk)
+	case ifMatch != "" && (!exists || existing["_etag"] != ifMatch):
+		return nil, false, newStatusError(http.StatusPreconditionFailed, "the item's etag does not match %s", ifMatch)
+	}
+
+	if partition == nil {
+		partition = map[string]map[string]any{}
+		c.items[pk] = partition
+	}
+	rid := ""
+	if exists {
+		rid, _ = existing["_rid"].(string)
+	}
+	s.stamp(item, rid)
+	partition[id] = item
+	return item, !exists, nil
+}
+
+func (s *store) readItem(dbID, containerID, pk, id string) (map[string]any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, err := s.container(dbID, containerID)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := c.items[pk][id]
+	if !ok {
+		return nil, newStatusError(http.StatusNotFound, "item %s does not exist in partition %s", id, pk)
+	}
+	return item, nil
+}
+
+func (s *store) deleteItem(dbID, containerID, pk, id, ifMatch string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.container(dbID, containerID)
+	if err != nil {
+		return err
+	}
+	item, ok := c.items[pk][id]
+	if !ok {
+		return newStatusError(http.StatusNotFound, "item %s does not exist in partition %s", id, pk)
+	}
+	if ifMatch != "" && item["_etag"] != ifMatch {
+		return newStatusError(http.StatusPreconditionFailed, "the item's etag does not match %s", ifMatch)
+	}
+	delete(c.items[pk], id)
+	return nil
+}
+
+// patchOperation is one operation of a partial document update
+type patchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// patchItem applies patch operations to a copy of an existing item and stores the result
+func (s *store) patchItem(dbID, containerID, pk, id string, ops []patchOperation, ifMatch string) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.container(dbID, containerID)
+	if err != nil {
+		return nil, err
+	}
+	existing, ok := c.items[pk][id]
+	if !ok {
+		return nil, newStatusError(http.StatusNotFound, "item %s does not exist in partition %s", id, pk)
+	}
+	if ifMatch != "" && existing["_etag"] != ifMatch {
+		return nil, newStatusError(http.StatusPreconditionFailed, "the item's etag does not match %s", ifMatch)
+	}
+
+	// Stored items are never mutated in place, since responses may still be encoding them
+	var item map[string]any
+	encoded, _ := json.Marshal(existing)
+	decodeJSON(encoded, &item)
+
+	for _, op := range ops {
+		if err := applyPatch(item, op); err != nil {
+			return nil, err
+		}
+	}
+	if newID, _ := item["id"].(string); newID != id || c.partitionKeyOf(item) != pk {
+		return nil, newStatusError(http.StatusBadRequest, "patch operations can't change an item's id or partition key")
+	}
+
+	s.stamp(item, existing["_rid"].(string))
+	c.items[pk][id] = item
+	return item, nil
+}
+
+func applyPatch(item map[string]any, op patchOperation) error {
+	path := splitPath(op.Path)
+	if len(path) == 0 {
+		return newStatusError(http.StatusBadRequest, "patch path %q is invalid", op.Path)
+	}
+
+	parent := item
+	for _, name := range path[:len(path)-1] {
+		child, ok := parent[name].(map[string]any)
+		if !ok {
+			return newStatusError(http.StatusBadRequest, "patch path %s does not exist", op.Path)
+		}
+		parent = child
+	}
+	name := path[len(path)-1]
+	_, exists := parent[name]
+
+	switch op.Op {
+	case "add", "set":
+		parent[name] = op.Value
+	case "replace":
+		if !exists {
+			return newStatusError(http.StatusBadRequest, "patch path %s does not exist", op.Path)
+		}
+		parent[name] = op.Value
+	case "remove":
+		if !exists {
+			return newStatusError(http.StatusBadRequest, "patch path %s does not exist", op.Path)
+		}
+		delete(parent, name)
+	case "incr":
+		current, ok := toFloat(parent[name])
+		if exists && !ok {
+			return newStatusError(http.StatusBadRequest, "patch path %s is not a number", op.Path)
+		}
+		delta, ok := toFloat(op.Value)
+		if !ok {
+			return newStatusError(http.StatusBadRequest, "incr requires a numeric value")
+		}
+		parent[name] = json.Number(strconv.FormatFloat(current+delta, 'f', -1, 64))
+	default:
+		return newStatusError(http.StatusBadRequest, "unsupported patch operation %q", op.Op)
+	}
+	return nil
+}
+
+// queryItems returns the items matching a query, in a stable order so continuation offsets are
+// consistent between pages. An empty pk queries across all partitions.
+func (s *store) queryItems(dbID, containerID, pk string, q *query, params map[string]any) ([]map[string]any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, err := s.container(dbID, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(c.items))
+	if pk != "" {
+		keys = append(keys, pk)
+	} else {
+		for key := range c.items {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	var result []map[string]any
+	for _, key := range keys {
+		partition := make([]map[string]any, 0, len(c.items[key]))
+		for _, item := range c.items[key] {
+			partition = append(partition, item)
+		}
+		sortByID(partition)
+
+		for _, item := range partition {
+			if q.where != nil {
+				ok, err := q.where.matches(item, params)
+				if err != nil {
+					return nil, newStatusError(http.StatusBadRequest, "%v", err)
+				}
+				if !ok {
+					continue
+				}
+			}
+			result = append(result, item)
+			if q.top > 0 && len(result) == q.top {
+				return result, nil
+			}
+		}
+	}
+	return result, nil
+}
+
+func sortByID(resources []map[string]any) {
+	sort.Slice(resources, func(i, j int) bool {
+		a, _ := resources[i]["id"].(string)
+		b, _ := resources[j]["id"].(string)
+		return a < b
+	})
+}
+
+// splitPath splits a path such as /address/city into its property names
+func splitPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,235 @@
+package harness
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ErrRegression is returned by the check command when a run regressed beyond the tolerances
+var ErrRegression = errors.New("performance regressed")
+
+// Tolerances are the largest regressions Check accepts, as fractions of the baseline
+type Tolerances struct {
+	ThroughputDrop float64
+	P99Increase    float64
+}
+
+// CheckResult compares a run with the baseline for its implementation and operation
+type CheckResult struct {
+	Implementation string
+	Operation      string
+	Metrics        []*MetricDiff
+}
+
+// MetricDiff compares one metric with its baseline. Metrics without a Limit are only reported.
+type MetricDiff struct {
+	Name     string
+	Baseline float64
+	Current  float64
+	// Change is relative to the baseline, e.g. 0.05 for 5% higher, or the absolute difference if
+	// Absolute is set because the baseline is zero
+	Change   float64
+	Absolute bool
+	// Limit is the largest change in the worse direction that is accepted, or zero for no limit
+	Limit          float64
+	HigherIsBetter bool
+	Regressed      bool
+}
+
+// Check compares current with baseline. Metrics that are zero in both, such as the percentiles of
+// Rust results, are skipped. A metric with a zero baseline is compared by absolute difference, and
+// regresses beyond any limit once it gets worse, as does an error rate that rises from zero.
+func Check(baseline, current *Report, tolerances Tolerances) *CheckResult {
+	result := &CheckResult{Implementation: current.Metadata.Implementation, Operation: current.Metadata.Operation}
+	add := func(name string, base, cur, limit float64, higherIsBetter bool) *MetricDiff {
+		if base == 0 && cur == 0 {
+			return nil
+		}
+		diff := &MetricDiff{Name: name, Baseline: base, Current: cur, Limit: limit, HigherIsBetter: higherIsBetter}
+		if base == 0 {
+			diff.Change, diff.Absolute = cur, true
+		} else {
+			diff.Change = (cur - base) / base
+		}
+		worse := diff.Change
+		if higherIsBetter {
+			worse = -worse
+		}
+		if diff.Absolute {
+			diff.Regressed = limit > 0 && worse > 0
+		} else {
+			diff.Regressed = limit > 0 && worse > limit
+		}
+		result.Metrics = append(result.Metrics, diff)
+		return diff
+	}
+
+	b, c := baseline.Results, current.Results
+	add("ops/sec", b.OpsPerSecond, c.OpsPerSecond, tolerances.ThroughputDrop, true)
+	add("latency ms", b.LatencyMs, c.LatencyMs, 0, false)
+	add("p50 ms", b.P50Ms, c.P50Ms, 0, false)
+	add("p99 ms", b.P99Ms, c.P99Ms, tolerances.P99Increase, false)
+	add("p99.9 ms", b.P999Ms, c.P999Ms, 0, false)
+	add("allocs/op", b.AllocsPerOp, c.AllocsPerOp, 0, false)
+	if diff := add("error rate", b.ErrorRate, c.ErrorRate, 0, false); diff != nil && diff.Absolute {
+		// Errors in a run whose baseline had none point to a bug rather than noise
+		diff.Regressed = true
+	}
+	add("cpu %", cpuPercent(b), cpuPercent(c), 0, false)
+	return result
+}
+
+// cpuPercent returns the CPU usage of a run, or zero if it wasn't sampled
+func cpuPercent(r *Results) float64 {
+	if r.Process == nil {
+		return 0
+	}
+	return r.Process.CPUPercent
+}
+
+// Regressed reports whether any metric regressed beyond its limit
+func (r *CheckResult) Regressed() bool {
+	for _, m := range r.Metrics {
+		if m.Regressed {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes a diff of every metric against the baseline
+func (r *CheckResult) Print(w io.Writer, baselineFile string) {
+	fmt.Fprintf(w, "%s %s vs %s:\n", r.Implementation, r.Operation, baselineFile)
+	fmt.Fprintf(w, "  %-12s %12s %12s %9s %9s\n", "metric", "baseline", "current", "change", "limit")
+	for _, m := range r.Metrics {
+		limit, status := "", ""
+		if m.Limit > 0 {
+			if m.HigherIsBetter {
+				limit = fmt.Sprintf("%+.2f%%", -m.Limit*100)
+			} else {
+				limit = fmt.Sprintf("%+.2f%%", m.Limit*100)
+			}
+			status = "ok"
+			if m.Regressed {
+				status = "REGRESSED"
+			}
+		}
+		change := fmt.Sprintf("%+8.2f%%", m.Change*100)
+		if m.Absolute {
+			change = fmt.Sprintf("%+9.2f", m.Change)
+		}
+		if m.Regressed && m.Limit == 0 {
+			status = "REGRESSED"
+		}
+		line := fmt.Sprintf("  %-12s %12.2f %12.2f %s %9s  %s", m.Name, m.Baseline, m.Current, change, limit, status)
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
+
+// BaselinePath returns the baseline file for an implementation and operation in dir, e.g.
+// dir/go-wrapper-pointRead.json
+func BaselinePath(dir, implementation, operation string) string {
+	name := strings.ReplaceAll(strings.ToLower(implementation), " ", "-")
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.json", name, operation))
+}
+
+// NewCheckCommand returns a command that checks results files against stored baselines and exits
+// with a non-zero status if any regressed
+func NewCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check <results file>...",
+		Short: "Check benchmark results against a stored baseline",
+		Long: `Compares JSON results files, written with --output json or by rust-bench's --output-file, with the
+baseline for the same implementation and operation. Exits with a non-zero status if throughput dropped or
+p99 latency rose by more than the tolerances, or if operations failed when none did in the baseline. With --update the results become the new baselines.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runCheck,
+	}
+	cmd.Flags().String("baselines", "baselines", "Directory holding a baseline results file per implementation and operation")
+	cmd.Flags().Float64("max-throughput-drop", 0.05, "Largest accepted drop in ops/sec, as a fraction of the baseline")
+	cmd.Flags().Float64("max-p99-increase", 0.10, "Largest accepted increase in p99 latency, as a fraction of the baseline")
+	cmd.Flags().Bool("update", false, "Write the results as the new baselines instead of checking them")
+	return cmd
+}
+
+func runCheck(cmd *cobra.Command, paths []string) error {
+	dir, err := cmd.Flags().GetString("baselines")
+	if err != nil {
+		return fmt.Errorf("failed to get baselines: %w", err)
+	}
+
+	var tolerances Tolerances
+	if tolerances.ThroughputDrop, err = cmd.Flags().GetFloat64("max-throughput-drop"); err != nil {
+		return fmt.Errorf("failed to get max-throughput-drop: %w", err)
+	}
+	if tolerances.P99Increase, err = cmd.Flags().GetFloat64("max-p99-increase"); err != nil {
+		return fmt.Errorf("failed to get max-p99-increase: %w", err)
+	}
+	if tolerances.ThroughputDrop < 0 || tolerances.P99Increase < 0 {
+		return fmt.Errorf("tolerances must not be negative")
+	}
+
+	update, err := cmd.Flags().GetBool("update")
+	if err != nil {
+		return fmt.Errorf("failed to get update: %w", err)
+	}
+
+	regressed := 0
+	for i, path := range paths {
+		current, err := LoadReport(path)
+		if err != nil {
+			return err
+		}
+		baselinePath := BaselinePath(dir, current.Metadata.Implementation, current.Metadata.Operation)
+
+		if update {
+			if err := writeBaseline(baselinePath, current); err != nil {
+				return err
+			}
+			fmt.Printf("Updated %s\n", baselinePath)
+			continue
+		}
+
+		baseline, err := LoadReport(baselinePath)
+		if err != nil {
+			return fmt.Errorf("failed to load baseline: %w", err)
+		}
+		if baseline.Metadata.Implementation != current.Metadata.Implementation || baseline.Metadata.Operation != current.Metadata.Operation {
+			return fmt.Errorf("%s holds %s %s results", baselinePath, baseline.Metadata.Implementation, baseline.Metadata.Operation)
+		}
+
+		result := Check(baseline, current, tolerances)
+		if i > 0 {
+			fmt.Println()
+		}
+		result.Print(os.Stdout, baselinePath)
+		if result.Regressed() {
+			regressed++
+		}
+	}
+
+	if regressed > 0 {
+		return fmt.Errorf("%w in %d of %d results", ErrRegression, regressed, len(paths))
+	}
+	return nil
+}
+
+// writeBaseline stores report as a baseline. Rust results are stored in the normalized report format.
+func writeBaseline(path string, report *Report) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create baselines directory: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
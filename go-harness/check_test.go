@@ -0,0 +1,56 @@
+package harness
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	report := func(opsPerSecond, p99 float64) *Report {
+		return &Report{
+			Metadata: RunMetadata{Implementation: "Go Wrapper", Operation: "pointRead"},
+			Results:  &Results{OpsPerSecond: opsPerSecond, LatencyMs: 2, P99Ms: p99},
+		}
+	}
+	baseline := report(1000, 10)
+	tolerances := Tolerances{ThroughputDrop: 0.05, P99Increase: 0.10}
+
+	if result := Check(baseline, report(960, 10.5), tolerances); result.Regressed() {
+		t.Errorf("expected changes within tolerance to pass, got %+v", result.Metrics)
+	}
+	if result := Check(baseline, report(940, 9), tolerances); !result.Regressed() || !result.Metrics[0].Regressed {
+		t.Errorf("expected a 6%% throughput drop to regress, got %+v", result.Metrics[0])
+	}
+	if result := Check(baseline, report(1100, 11.5), tolerances); !result.Regressed() {
+		t.Error("expected a 15% p99 increase to regress")
+	}
+
+	// Throughput dropping to zero is a 100% drop
+	if result := Check(baseline, report(0, 10), tolerances); !result.Metrics[0].Regressed || result.Metrics[0].Change != -1 {
+		t.Errorf("expected throughput dropping to zero to regress, got %+v", result.Metrics[0])
+	}
+
+	// A zero baseline is compared by absolute difference, and any new errors regress
+	failing := report(1000, 10)
+	failing.Results.ErrorRate = 0.05
+	result := Check(baseline, failing, tolerances)
+	errorRate := result.Metrics[len(result.Metrics)-1]
+	if errorRate.Name != "error rate" || !errorRate.Absolute || errorRate.Change != 0.05 || !result.Regressed() {
+		t.Errorf("expected an error rate rising from zero to regress, got %+v", errorRate)
+	}
+	if result := Check(failing, baseline, tolerances); result.Regressed() {
+		t.Errorf("expected an error rate dropping to zero to pass, got %+v", result.Metrics)
+	}
+
+	// Rust results have no percentiles, so only throughput and mean latency are compared
+	rust := &Report{Results: &Results{OpsPerSecond: 1000, LatencyMs: 2}}
+	if result := Check(rust, rust, tolerances); len(result.Metrics) != 2 {
+		t.Errorf("expected only the metrics present in both to be compared, got %d", len(result.Metrics))
+	}
+}
+
+func TestBaselinePath(t *testing.T) {
+	if got := BaselinePath("baselines", "Go Wrapper", "pointRead"); got != filepath.Join("baselines", "go-wrapper-pointRead.json") {
+		t.Errorf("unexpected baseline path %s", got)
+	}
+}
@@ -0,0 +1,348 @@
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// rustResults is the JSON written by rust-bench's --output-file
+type rustResults struct {
+	TotalOps      int     `json:"total_ops"`
+	ElapsedTimeMs int64   `json:"elapsed_time_ms"`
+	OpsPerSecond  float64 `json:"ops_per_second"`
+	LatencyMs     float64 `json:"latency_ms"`
+}
+
+// LoadReport reads a results file written in JSON by the Go benchmarks or by rust-bench. Rust
+// results only have throughput and mean latency, and are reported as point reads by "Rust".
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch {
+	case fields["schemaVersion"] != nil:
+		var report Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if report.SchemaVersion > ReportSchemaVersion {
+			return nil, fmt.Errorf("%s: unsupported schema version %d", path, report.SchemaVersion)
+		}
+		if report.Results == nil {
+			return nil, fmt.Errorf("%s: no results", path)
+		}
+		return &report, nil
+	case fields["total_ops"] != nil:
+		var rust rustResults
+		if err := json.Unmarshal(data, &rust); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return &Report{
+			SchemaVersion: ReportSchemaVersion,
+			Metadata:      RunMetadata{Implementation: "Rust", Operation: "pointRead"},
+			Results: &Results{
+				TotalOps:     rust.TotalOps,
+				ElapsedTime:  time.Duration(rust.ElapsedTimeMs) * time.Millisecond,
+				OpsPerSecond: rust.OpsPerSecond,
+				LatencyMs:    rust.LatencyMs,
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("%s: not a benchmark results file", path)
+}
+
+// Comparison compares the implementations that ran one operation
+type Comparison struct {
+	Operation string
+	Baseline  string
+	Rows      []*ComparisonRow
+}
+
+// ComparisonRow combines every run of one implementation. Each run, or each iteration of a
+// multi-iteration run, is one sample of the statistics.
+type ComparisonRow struct {
+	Implementation string
+	Samples        int
+	TotalOps       int
+	ElapsedTime    time.Duration
+	OpsPerSecond   Statistic
+	LatencyMs      Statistic
+	P99Ms          Statistic
+
+	// CPUPerCorePercent is the mean over the runs that sampled process CPU usage, or zero if none did
+	CPUPerCorePercent float64
+
+	// Relative change from the baseline, e.g. -0.05 for 5% lower. The difference is significant if
+	// Welch's t-test rejects equal means at 95% confidence, and can only be tested with at least two
+	// samples on each side.
+	OpsPerSecondDelta       float64
+	LatencyDelta            float64
+	Testable                bool
+	OpsPerSecondSignificant bool
+	LatencySignificant      bool
+
+	samples []*Summary
+	cpu     []float64
+}
+
+// Compare groups reports by operation and implementation, in the order they first appear, and
+// compares every implementation with baseline, or the first implementation if baseline is empty
+func Compare(reports []*Report, baseline string) ([]*Comparison, error) {
+	var comparisons []*Comparison
+	for _, report := range reports {
+		var comparison *Comparison
+		for _, c := range comparisons {
+			if c.Operation == report.Metadata.Operation {
+				comparison = c
+			}
+		}
+		if comparison == nil {
+			comparison = &Comparison{Operation: report.Metadata.Operation}
+			comparisons = append(comparisons, comparison)
+		}
+
+		var row *ComparisonRow
+		for _, r := range comparison.Rows {
+			if r.Implementation == report.Metadata.Implementation {
+				row = r
+			}
+		}
+		if row == nil {
+			row = &ComparisonRow{Implementation: report.Metadata.Implementation}
+			comparison.Rows = append(comparison.Rows, row)
+		}
+
+		results := report.Results
+		row.TotalOps += results.TotalOps
+		row.ElapsedTime += results.ElapsedTime
+		if results.Process != nil && results.Process.CPUPerCorePercent > 0 {
+			row.cpu = append(row.cpu, results.Process.CPUPerCorePercent)
+		}
+		if results.Iterations != nil {
+			for _, run := range results.Iterations.Runs {
+				row.samples = append(row.samples, &run.Summary)
+			}
+		} else {
+			row.samples = append(row.samples, &Summary{
+				TotalOps:     results.TotalOps,
+				OpsPerSecond: results.OpsPerSecond,
+				LatencyMs:    results.LatencyMs,
+				P99Ms:        results.P99Ms,
+			})
+		}
+	}
+
+	for _, comparison := range comparisons {
+		base := comparison.Rows[0]
+		if baseline != "" {
+			base = nil
+			for _, row := range comparison.Rows {
+				if row.Implementation == baseline {
+					base = row
+				}
+			}
+			if base == nil {
+				return nil, fmt.Errorf("no %s results for baseline %s, only %s", comparison.Operation, baseline, implementationList(comparison.Rows))
+			}
+		}
+		comparison.Baseline = base.Implementation
+
+		for _, row := range comparison.Rows {
+			row.Samples = len(row.samples)
+			row.OpsPerSecond = row.statistic(func(s *Summary) float64 { return s.OpsPerSecond })
+			row.LatencyMs = row.statistic(func(s *Summary) float64 { return s.LatencyMs })
+			row.P99Ms = row.statistic(func(s *Summary) float64 { return s.P99Ms })
+			row.CPUPerCorePercent = newStatistic(row.cpu).Mean
+		}
+		for _, row := range comparison.Rows {
+			row.OpsPerSecondDelta = relativeDelta(base.OpsPerSecond, row.OpsPerSecond)
+			row.LatencyDelta = relativeDelta(base.LatencyMs, row.LatencyMs)
+			row.Testable = base.Samples > 1 && row.Samples > 1
+			if row.Testable {
+				row.OpsPerSecondSignificant = welchSignificant(base.OpsPerSecond, base.Samples, row.OpsPerSecond, row.Samples)
+				row.LatencySignificant = welchSignificant(base.LatencyMs, base.Samples, row.LatencyMs, row.Samples)
+			}
+		}
+	}
+	return comparisons, nil
+}
+
+func (r *ComparisonRow) statistic(value func(*Summary) float64) Statistic {
+	values := make([]float64, len(r.samples))
+	for i, s := range r.samples {
+		values[i] = value(s)
+	}
+	return newStatistic(values)
+}
+
+func relativeDelta(base, other Statistic) float64 {
+	if base.Mean == 0 {
+		return 0
+	}
+	return (other.Mean - base.Mean) / base.Mean
+}
+
+// welchSignificant reports whether Welch's t-test rejects equal means at 95% confidence
+func welchSignificant(a Statistic, n int, b Statistic, m int) bool {
+	va := a.StdDev * a.StdDev / float64(n)
+	vb := b.StdDev * b.StdDev / float64(m)
+	if va+vb == 0 {
+		return a.Mean != b.Mean
+	}
+	t := math.Abs(a.Mean-b.Mean) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(n-1) + vb*vb/float64(m-1))
+	return t > tCritical95(max(int(df), 1))
+}
+
+// WriteComparison writes a markdown table per operation, in the layout of the README's results
+func WriteComparison(w io.Writer, comparisons []*Comparison) error {
+	untestable := false
+	for i, comparison := range comparisons {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "### %s\n\n", comparison.Operation)
+		fmt.Fprintf(w, "| Implementation | Runs | Total Ops | Duration (ms) | Ops/sec | Latency (ms) | p99 (ms) | CPU per core | Ops/sec vs %s | Latency vs %s |\n", comparison.Baseline, comparison.Baseline)
+		fmt.Fprintf(w, "|---------------|------|-----------|---------------|---------|--------------|----------|--------------|---------|---------|\n")
+		for _, row := range comparison.Rows {
+			opsDelta, latencyDelta := "baseline", "baseline"
+			if row.Implementation != comparison.Baseline {
+				opsDelta = formatDelta(row.OpsPerSecondDelta, row.Testable, row.OpsPerSecondSignificant)
+				latencyDelta = formatDelta(row.LatencyDelta, row.Testable, row.LatencySignificant)
+				untestable = untestable || !row.Testable
+			}
+			p99 := "-"
+			if row.P99Ms.Min > 0 {
+				p99 = formatStatistic(row.P99Ms, row.Samples)
+			}
+			cpu := "-"
+			if row.CPUPerCorePercent > 0 {
+				cpu = fmt.Sprintf("%.1f%%", row.CPUPerCorePercent)
+			}
+			_, err := fmt.Fprintf(w, "| %s | %d | %d | %d | %s | %s | %s | %s | %s | %s |\n",
+				row.Implementation,
+				row.Samples,
+				row.TotalOps,
+				row.ElapsedTime.Milliseconds(),
+				formatStatistic(row.OpsPerSecond, row.Samples),
+				formatStatistic(row.LatencyMs, row.Samples),
+				p99,
+				cpu,
+				opsDelta,
+				latencyDelta)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nValues are the mean ± 95%% confidence interval across runs (or iterations). `*` marks differences from the baseline that are significant at 95%% confidence (Welch's t-test).\n")
+	if untestable {
+		fmt.Fprintf(w, "`?` marks differences that can't be tested because an implementation has fewer than two runs.\n")
+	}
+	return nil
+}
+
+func formatStatistic(s Statistic, samples int) string {
+	if samples < 2 {
+		return fmt.Sprintf("%.2f", s.Mean)
+	}
+	return s.String()
+}
+
+func formatDelta(delta float64, testable, significant bool) string {
+	marker := ""
+	switch {
+	case !testable:
+		marker = " ?"
+	case significant:
+		marker = " *"
+	}
+	return fmt.Sprintf("%+.2f%%%s", delta*100, marker)
+}
+
+// NewCompareCommand returns a command that compares result files from each implementation
+func NewCompareCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare <results file>...",
+		Short: "Compare benchmark results across implementations",
+		Long: `Reads JSON results files written with --output json by the Go benchmarks, or with --output-file
+by rust-bench, and renders a markdown table per operation comparing each implementation to a baseline.
+Several files from the same implementation, or a run with --iterations, are combined so the
+differences can be tested for significance.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runCompare(cmd, args); err != nil {
+				fmt.Printf("Error comparing results: %v\n", err)
+				return
+			}
+		},
+	}
+	cmd.Flags().String("baseline", "", "Implementation to compare the others to (default: the first one given)")
+	cmd.Flags().String("output-file", "", "Write the comparison to this file instead of stdout")
+	return cmd
+}
+
+func runCompare(cmd *cobra.Command, paths []string) error {
+	baseline, err := cmd.Flags().GetString("baseline")
+	if err != nil {
+		return fmt.Errorf("failed to get baseline: %w", err)
+	}
+
+	file, err := cmd.Flags().GetString("output-file")
+	if err != nil {
+		return fmt.Errorf("failed to get output-file: %w", err)
+	}
+
+	reports := make([]*Report, len(paths))
+	for i, path := range paths {
+		if reports[i], err = LoadReport(path); err != nil {
+			return err
+		}
+	}
+
+	comparisons, err := Compare(reports, baseline)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := WriteComparison(bw, comparisons); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// implementationList formats implementation names for error messages
+func implementationList(rows []*ComparisonRow) string {
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = row.Implementation
+	}
+	return strings.Join(names, ", ")
+}
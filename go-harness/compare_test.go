@@ -0,0 +1,101 @@
+package harness
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, name string, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func iterationReport(implementation string, opsPerSecond ...float64) *Report {
+	results := &Results{}
+	var runs []*IterationRun
+	for i, ops := range opsPerSecond {
+		runs = append(runs, &IterationRun{Iteration: i + 1, ElapsedTime: time.Second, Summary: Summary{TotalOps: int(ops), OpsPerSecond: ops, LatencyMs: 1000 / ops, P99Ms: 2}})
+		results.TotalOps += int(ops)
+		results.ElapsedTime += time.Second
+	}
+	results.Iterations = newIterationResults(runs, 0)
+	return &Report{
+		SchemaVersion: ReportSchemaVersion,
+		Metadata:      RunMetadata{Implementation: implementation, Operation: "pointRead"},
+		Results:       results,
+	}
+}
+
+func TestLoadReportRust(t *testing.T) {
+	path := writeTestFile(t, "rust.json", map[string]any{"total_ops": 502760, "elapsed_time_ms": 60002, "ops_per_second": 8378.97, "latency_ms": 1.91})
+
+	report, err := LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Metadata.Implementation != "Rust" || report.Metadata.Operation != "pointRead" {
+		t.Errorf("unexpected metadata %+v", report.Metadata)
+	}
+	if r := report.Results; r.TotalOps != 502760 || r.ElapsedTime != 60002*time.Millisecond || r.OpsPerSecond != 8378.97 || r.LatencyMs != 1.91 {
+		t.Errorf("unexpected results %+v", r)
+	}
+
+	if _, err := LoadReport(writeTestFile(t, "other.json", map[string]any{"foo": 1})); err == nil {
+		t.Error("expected an error for an unrecognized file")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	path := writeTestFile(t, "go.json", iterationReport("Go", 1000, 1010, 990, 1005))
+	goReport, err := LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports := []*Report{
+		iterationReport("Rust", 1200, 1190, 1210, 1205),
+		goReport,
+		iterationReport("Go Wrapper", 1195, 1215, 1200, 1185),
+		iterationReport("Go Wrapper", 1190),
+	}
+	comparisons, err := Compare(reports, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(comparisons) != 1 || comparisons[0].Baseline != "Rust" || len(comparisons[0].Rows) != 3 {
+		t.Fatalf("expected one comparison of three implementations against Rust, got %+v", comparisons)
+	}
+	goRow, wrapperRow := comparisons[0].Rows[1], comparisons[0].Rows[2]
+	if math.Abs(goRow.OpsPerSecondDelta-(1001.25-1201.25)/1201.25) > 1e-9 || !goRow.OpsPerSecondSignificant {
+		t.Errorf("expected Go to be significantly slower, got %+v", goRow)
+	}
+	if wrapperRow.Samples != 5 || wrapperRow.OpsPerSecondSignificant {
+		t.Errorf("expected 5 wrapper samples without a significant difference, got %+v", wrapperRow)
+	}
+
+	var out strings.Builder
+	if err := WriteComparison(&out, comparisons); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "| Go | 4 | 4005 | 4000 | 1001.25 ± ") || !strings.Contains(out.String(), "| -16.65% * |") {
+		t.Errorf("unexpected table:\n%s", out.String())
+	}
+
+	if _, err := Compare(reports, "Java"); err == nil {
+		t.Error("expected an error for a missing baseline")
+	}
+}
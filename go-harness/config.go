@@ -0,0 +1,287 @@
+package harness
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Config controls a benchmark run
+type Config struct {
+	ItemCount      int
+	PartitionCount int
+	Workers        int
+	Duration       time.Duration
+
+	// Warmup runs the workload for this long before measuring. RampSteps, if set, replaces Workers
+	// with a series of steps of Duration each, using the given number of workers.
+	Warmup    time.Duration
+	RampSteps []int
+
+	// Iterations repeats the measured run, pausing for Cooldown in between, to measure how much
+	// results vary. Zero or one runs once.
+	Iterations int
+	Cooldown   time.Duration
+
+	// Distribution chooses the keys operations target. Seed seeds every worker's random source, so
+	// each worker's sequence of keys and operations is reproducible.
+	Distribution KeyDistribution
+	Seed         int64
+
+	// Rate is the target operations per second for an open-loop run, and Arrival its arrival
+	// process. Zero runs closed-loop.
+	Rate    float64
+	Arrival string
+
+	// MaxErrors aborts the run once more operations than this have failed; zero means no limit.
+	// ErrorSample is the fraction of errors logged as they happen.
+	MaxErrors   int
+	ErrorSample float64
+
+	// ReportInterval is how often throughput and latency for the interval just ended are printed,
+	// and also written to ReportFile as JSON lines if it is set. Zero disables interval reports.
+	ReportInterval time.Duration
+	ReportFile     string
+
+	Output OutputOptions
+}
+
+// AddFlags registers the flags read by ConfigFromFlags on cmd
+func AddFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("item-count", "i", 10000, "Total number of items in the database")
+	cmd.Flags().DurationP("duration", "t", 60*time.Second, "Duration to run the benchmark")
+	cmd.Flags().IntP("partition-count", "p", 10, "Number of partitions the items are distributed across")
+	cmd.Flags().IntP("workers", "w", runtime.NumCPU(), "Number of concurrent workers")
+	cmd.Flags().Duration("warmup", 0, "Run the workload for this long before measuring, to exclude connection setup and client initialization")
+	cmd.Flags().IntSlice("ramp", nil, "Comma-separated worker counts to step through, each for --duration, e.g. 1,2,4,8 (overrides --workers)")
+	cmd.Flags().Int("iterations", 1, "Repeat the measured run this many times and report the mean, standard deviation and 95% confidence interval")
+	cmd.Flags().Duration("cooldown", 0, "Pause for this long between iterations")
+	cmd.Flags().String("distribution", DistributionUniform, "Key distribution: uniform, zipfian, hotspot, sequential or latest (zipfian over the most recently seeded items)")
+	cmd.Flags().Float64("zipf-exponent", 1.1, "Skew of the zipfian and latest distributions, greater than 1")
+	cmd.Flags().Float64("hotspot-fraction", 0.2, "Fraction of the items that are hot in the hotspot distribution")
+	cmd.Flags().Float64("hotspot-access", 0.8, "Fraction of operations that target hot items in the hotspot distribution")
+	cmd.Flags().Int64("seed", 0, "Random seed for key and operation selection and written documents (0 picks one from the clock, which is reported)")
+	cmd.Flags().Float64("rate", 0, "Target operations per second, at most 1e6; schedules operations open-loop instead of running each worker back-to-back")
+	cmd.Flags().String("arrival", ArrivalFixed, "Arrival process for --rate: fixed or poisson")
+	cmd.Flags().Int("max-errors", 0, "Abort the run once more than this many operations have failed (0 for no limit)")
+	cmd.Flags().Float64("error-sample", 0, "Fraction of failed operations to log as they happen, between 0 and 1")
+	cmd.Flags().Duration("report-interval", 5*time.Second, "How often to report throughput, latency percentiles, errors and heap usage for the interval just ended (0 disables interval reports)")
+	cmd.Flags().String("report-file", "", "Also write every interval report to this file as a line of JSON")
+	cmd.Flags().StringP("output", "o", OutputMarkdown, "Results format: markdown, json or csv")
+	cmd.Flags().String("output-file", "", "Write results to this file instead of stdout")
+}
+
+// ConfigFromFlags reads and validates the flags registered by AddFlags
+func ConfigFromFlags(cmd *cobra.Command) (*Config, error) {
+	itemCount, err := cmd.Flags().GetInt("item-count")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item-count: %w", err)
+	}
+
+	duration, err := cmd.Flags().GetDuration("duration")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duration: %w", err)
+	}
+
+	partitionCount, err := cmd.Flags().GetInt("partition-count")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition-count: %w", err)
+	}
+
+	workers, err := cmd.Flags().GetInt("workers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workers: %w", err)
+	}
+
+	warmup, err := cmd.Flags().GetDuration("warmup")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warmup: %w", err)
+	}
+
+	rampSteps, err := cmd.Flags().GetIntSlice("ramp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ramp: %w", err)
+	}
+
+	iterations, err := cmd.Flags().GetInt("iterations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get iterations: %w", err)
+	}
+
+	cooldown, err := cmd.Flags().GetDuration("cooldown")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cooldown: %w", err)
+	}
+
+	distribution := KeyDistribution{}
+	if distribution.Name, err = cmd.Flags().GetString("distribution"); err != nil {
+		return nil, fmt.Errorf("failed to get distribution: %w", err)
+	}
+	if distribution.ZipfExponent, err = cmd.Flags().GetFloat64("zipf-exponent"); err != nil {
+		return nil, fmt.Errorf("failed to get zipf-exponent: %w", err)
+	}
+	if distribution.HotspotFraction, err = cmd.Flags().GetFloat64("hotspot-fraction"); err != nil {
+		return nil, fmt.Errorf("failed to get hotspot-fraction: %w", err)
+	}
+	if distribution.HotspotAccess, err = cmd.Flags().GetFloat64("hotspot-access"); err != nil {
+		return nil, fmt.Errorf("failed to get hotspot-access: %w", err)
+	}
+	if err := distribution.validate(); err != nil {
+		return nil, err
+	}
+
+	seed, err := cmd.Flags().GetInt64("seed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed: %w", err)
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	rate, err := cmd.Flags().GetFloat64("rate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate: %w", err)
+	}
+
+	arrival, err := cmd.Flags().GetString("arrival")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get arrival: %w", err)
+	}
+
+	maxErrors, err := cmd.Flags().GetInt("max-errors")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max-errors: %w", err)
+	}
+
+	errorSample, err := cmd.Flags().GetFloat64("error-sample")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get error-sample: %w", err)
+	}
+
+	reportInterval, err := cmd.Flags().GetDuration("report-interval")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report-interval: %w", err)
+	}
+
+	reportFile, err := cmd.Flags().GetString("report-file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report-file: %w", err)
+	}
+
+	format, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output: %w", err)
+	}
+
+	file, err := cmd.Flags().GetString("output-file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output-file: %w", err)
+	}
+
+	if itemCount <= 0 || partitionCount <= 0 || workers <= 0 || duration <= 0 {
+		return nil, fmt.Errorf("item-count, partition-count, workers and duration must be positive")
+	}
+	if warmup < 0 {
+		return nil, fmt.Errorf("warmup must not be negative")
+	}
+	for _, n := range rampSteps {
+		if n <= 0 {
+			return nil, fmt.Errorf("ramp worker counts must be positive")
+		}
+	}
+	if iterations <= 0 {
+		return nil, fmt.Errorf("iterations must be positive")
+	}
+	if cooldown < 0 {
+		return nil, fmt.Errorf("cooldown must not be negative")
+	}
+	if len(rampSteps) > 0 && iterations > 1 {
+		return nil, fmt.Errorf("ramp and iterations can't be combined")
+	}
+	if len(rampSteps) > 0 && rate > 0 {
+		return nil, fmt.Errorf("ramp and rate can't be combined")
+	}
+	if !(rate >= 0 && rate <= MaxRate) {
+		return nil, fmt.Errorf("rate must be between 0 and %g", MaxRate)
+	}
+	if arrival != ArrivalFixed && arrival != ArrivalPoisson {
+		return nil, fmt.Errorf("invalid arrival %q, expected %s or %s", arrival, ArrivalFixed, ArrivalPoisson)
+	}
+	if maxErrors < 0 {
+		return nil, fmt.Errorf("max-errors must not be negative")
+	}
+	if errorSample < 0 || errorSample > 1 {
+		return nil, fmt.Errorf("error-sample must be between 0 and 1")
+	}
+	if reportInterval < 0 {
+		return nil, fmt.Errorf("report-interval must not be negative")
+	}
+	if reportInterval == 0 && reportFile != "" {
+		return nil, fmt.Errorf("report-file requires a positive report-interval")
+	}
+	switch format {
+	case OutputMarkdown, OutputJSON, OutputCSV:
+	default:
+		return nil, fmt.Errorf("invalid output %q, expected one of: %s, %s, %s", format, OutputMarkdown, OutputJSON, OutputCSV)
+	}
+
+	return &Config{
+		ItemCount:      itemCount,
+		PartitionCount: partitionCount,
+		Workers:        workers,
+		Duration:       duration,
+		Warmup:         warmup,
+		RampSteps:      rampSteps,
+		Iterations:     iterations,
+		Cooldown:       cooldown,
+		Distribution:   distribution,
+		Seed:           seed,
+		Rate:           rate,
+		Arrival:        arrival,
+		MaxErrors:      maxErrors,
+		ErrorSample:    errorSample,
+		ReportInterval: reportInterval,
+		ReportFile:     reportFile,
+		Output:         OutputOptions{Format: format, File: file},
+	}, nil
+}
+
+// Print writes the run configuration to stdout
+func (c *Config) Print() {
+	fmt.Printf("Item count: %d\n", c.ItemCount)
+	fmt.Printf("Duration: %v\n", c.Duration)
+	fmt.Printf("Partition count: %d\n", c.PartitionCount)
+	if len(c.RampSteps) > 0 {
+		fmt.Printf("Ramp-up: %v workers, %v per step\n", c.RampSteps, c.Duration)
+	} else {
+		fmt.Printf("Workers: %d\n", c.Workers)
+	}
+	if c.Warmup > 0 {
+		fmt.Printf("Warmup: %v\n", c.Warmup)
+	}
+	if c.Iterations > 1 {
+		fmt.Printf("Iterations: %d, %v cooldown\n", c.Iterations, c.Cooldown)
+	}
+	fmt.Printf("Key distribution: %s\n", c.Distribution)
+	fmt.Printf("Seed: %d\n", c.Seed)
+	if c.Rate > 0 {
+		fmt.Printf("Target rate: %.1f ops/sec (%s arrivals)\n", c.Rate, c.Arrival)
+	}
+	if c.MaxErrors > 0 {
+		fmt.Printf("Max errors: %d\n", c.MaxErrors)
+	}
+	if c.ReportFile != "" {
+		fmt.Printf("Interval reports: every %v to %s\n", c.ReportInterval, c.ReportFile)
+	}
+}
+
+// MaxWorkers returns the most workers the run uses at once
+func (c *Config) MaxWorkers() int {
+	if len(c.RampSteps) == 0 {
+		return c.Workers
+	}
+	return slices.Max(c.RampSteps)
+}
@@ -0,0 +1,133 @@
+package harness
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Key distributions select which items a run's operations target
+const (
+	DistributionUniform    = "uniform"
+	DistributionZipfian    = "zipfian"
+	DistributionHotspot    = "hotspot"
+	DistributionSequential = "sequential"
+	DistributionLatest     = "latest"
+)
+
+// KeyDistribution configures how item keys are chosen
+type KeyDistribution struct {
+	Name string
+
+	// ZipfExponent is the skew of the zipfian and latest distributions; it must be greater than 1
+	ZipfExponent float64
+
+	// HotspotFraction of the items receive HotspotAccess of the operations in the hotspot distribution
+	HotspotFraction float64
+	HotspotAccess   float64
+}
+
+func (d KeyDistribution) validate() error {
+	switch d.Name {
+	case DistributionUniform, DistributionSequential:
+	case DistributionZipfian, DistributionLatest:
+		if d.ZipfExponent <= 1 {
+			return fmt.Errorf("zipf-exponent must be greater than 1")
+		}
+	case DistributionHotspot:
+		if d.HotspotFraction <= 0 || d.HotspotFraction > 1 || d.HotspotAccess < 0 || d.HotspotAccess > 1 {
+			return fmt.Errorf("hotspot-fraction must be in (0, 1] and hotspot-access in [0, 1]")
+		}
+	default:
+		return fmt.Errorf("invalid distribution %q, expected one of: %s, %s, %s, %s, %s", d.Name,
+			DistributionUniform, DistributionZipfian, DistributionHotspot, DistributionSequential, DistributionLatest)
+	}
+	return nil
+}
+
+// String describes the distribution and its parameters
+func (d KeyDistribution) String() string {
+	switch d.Name {
+	case DistributionZipfian, DistributionLatest:
+		return fmt.Sprintf("%s (exponent %.2f)", d.Name, d.ZipfExponent)
+	case DistributionHotspot:
+		return fmt.Sprintf("%s (%.0f%% of operations on %.0f%% of items)", d.Name, d.HotspotAccess*100, d.HotspotFraction*100)
+	}
+	return d.Name
+}
+
+// keyChooser picks the key of each operation for one worker
+type keyChooser interface {
+	next() int
+}
+
+// newKeyChooser creates the key chooser for one of workers workers, drawing from localRand
+func (d KeyDistribution) newKeyChooser(itemCount, workerID, workers int, localRand *rand.Rand) keyChooser {
+	switch d.Name {
+	case DistributionZipfian:
+		return &zipfKeys{zipf: rand.NewZipf(localRand, d.ZipfExponent, 1, uint64(itemCount-1))}
+	case DistributionLatest:
+		return &zipfKeys{zipf: rand.NewZipf(localRand, d.ZipfExponent, 1, uint64(itemCount-1)), last: itemCount - 1}
+	case DistributionHotspot:
+		return &hotspotKeys{
+			localRand: localRand,
+			itemCount: itemCount,
+			hotCount:  max(1, int(float64(itemCount)*d.HotspotFraction)),
+			access:    d.HotspotAccess,
+		}
+	case DistributionSequential:
+		// Workers start evenly spaced so they don't all read the same item at the same time
+		return &sequentialKeys{itemCount: itemCount, current: workerID * itemCount / workers}
+	}
+	return &uniformKeys{localRand: localRand, itemCount: itemCount}
+}
+
+type uniformKeys struct {
+	localRand *rand.Rand
+	itemCount int
+}
+
+func (k *uniformKeys) next() int {
+	return k.localRand.Intn(k.itemCount)
+}
+
+// zipfKeys favors low keys, or with last set, keys counting down from last. Since createDb seeds
+// items in order, the latest distribution favors the most recently inserted items.
+type zipfKeys struct {
+	zipf *rand.Zipf
+	last int
+}
+
+func (k *zipfKeys) next() int {
+	key := int(k.zipf.Uint64())
+	if k.last > 0 {
+		return k.last - key
+	}
+	return key
+}
+
+// hotspotKeys sends a fixed fraction of operations to the first hotCount items, and the rest to the others
+type hotspotKeys struct {
+	localRand *rand.Rand
+	itemCount int
+	hotCount  int
+	access    float64
+}
+
+func (k *hotspotKeys) next() int {
+	if k.hotCount == k.itemCount || k.localRand.Float64() < k.access {
+		return k.localRand.Intn(k.hotCount)
+	}
+	return k.hotCount + k.localRand.Intn(k.itemCount-k.hotCount)
+}
+
+// sequentialKeys cycles through every item in order
+type sequentialKeys struct {
+	itemCount int
+	current   int
+}
+
+func (k *sequentialKeys) next() int {
+	key := k.current
+	k.current = (k.current + 1) % k.itemCount
+	return key
+}
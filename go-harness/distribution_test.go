@@ -0,0 +1,57 @@
+package harness
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// keyCounts draws n keys from the distribution and counts how often each key was chosen
+func keyCounts(d KeyDistribution, itemCount, n int) []int {
+	keys := d.newKeyChooser(itemCount, 0, 1, rand.New(rand.NewSource(1)))
+	counts := make([]int, itemCount)
+	for range n {
+		counts[keys.next()]++
+	}
+	return counts
+}
+
+func TestKeyDistributions(t *testing.T) {
+	const itemCount, n = 100, 100000
+
+	counts := keyCounts(KeyDistribution{Name: DistributionZipfian, ZipfExponent: 1.1}, itemCount, n)
+	if counts[0] < counts[1] || counts[1] < counts[50] {
+		t.Errorf("expected zipfian to favor low keys, got %d, %d, %d for keys 0, 1, 50", counts[0], counts[1], counts[50])
+	}
+
+	counts = keyCounts(KeyDistribution{Name: DistributionLatest, ZipfExponent: 1.1}, itemCount, n)
+	if counts[itemCount-1] < counts[itemCount-2] || counts[itemCount-2] < counts[50] {
+		t.Errorf("expected latest to favor high keys, got %d, %d, %d for keys 99, 98, 50", counts[99], counts[98], counts[50])
+	}
+
+	counts = keyCounts(KeyDistribution{Name: DistributionHotspot, HotspotFraction: 0.1, HotspotAccess: 0.9}, itemCount, n)
+	hot := 0
+	for _, c := range counts[:10] {
+		hot += c
+	}
+	if share := float64(hot) / n; share < 0.88 || share > 0.92 {
+		t.Errorf("expected 90%% of operations on the hot items, got %.1f%%", share*100)
+	}
+
+	counts = keyCounts(KeyDistribution{Name: DistributionSequential}, itemCount, 2*itemCount)
+	for key, c := range counts {
+		if c != 2 {
+			t.Fatalf("expected sequential to visit every key twice, key %d was chosen %d times", key, c)
+		}
+	}
+}
+
+func TestKeyDistributionSeed(t *testing.T) {
+	d := KeyDistribution{Name: DistributionZipfian, ZipfExponent: 1.5}
+	a := d.newKeyChooser(1000, 0, 1, rand.New(rand.NewSource(42)))
+	b := d.newKeyChooser(1000, 0, 1, rand.New(rand.NewSource(42)))
+	for i := range 100 {
+		if ka, kb := a.next(), b.next(); ka != kb {
+			t.Fatalf("same seed produced different keys at step %d: %d != %d", i, ka, kb)
+		}
+	}
+}
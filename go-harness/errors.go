@@ -0,0 +1,52 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrorClassifier can be implemented by a Workload to group failed operations in results, e.g.
+// by status code. Workloads that don't implement it get defaultErrorClass.
+type ErrorClassifier interface {
+	// ClassifyError returns a short, stable label for err such as "429" or "404/1002"
+	ClassifyError(err error) string
+}
+
+// defaultErrorClass separates timeouts from other failures
+func defaultErrorClass(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return "error"
+}
+
+// StatusClass formats an HTTP status code and optional sub-status as an error class, e.g. "429/3200"
+func StatusClass(statusCode, subStatus int) string {
+	if subStatus != 0 {
+		return fmt.Sprintf("%d/%d", statusCode, subStatus)
+	}
+	return fmt.Sprintf("%d", statusCode)
+}
+
+// formatCounts renders counts as "class=n, class=n", most frequent first
+func formatCounts(counts map[string]int) string {
+	classes := make([]string, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if counts[classes[i]] != counts[classes[j]] {
+			return counts[classes[i]] > counts[classes[j]]
+		}
+		return classes[i] < classes[j]
+	})
+
+	parts := make([]string, len(classes))
+	for i, class := range classes {
+		parts[i] = fmt.Sprintf("%s=%d", class, counts[class])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,63 @@
+// Package harness runs closed-loop or open-loop benchmarks against a Workload and reports
+// throughput and latency statistics. Each benchmark implementation only provides the Operation that
+// issues a single request; worker scheduling, progress reporting, statistics and output are shared.
+package harness
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+)
+
+// Workload creates the Operations that benchmark workers execute
+type Workload interface {
+	// Name identifies the workload in results, e.g. "pointRead"
+	Name() string
+	// NewWorker returns the Operation that worker workerID calls repeatedly.
+	// It is only ever called by one goroutine at a time, so it may hold per-worker state.
+	NewWorker(workerID int) (Operation, error)
+}
+
+// Operation issues a single benchmarked request
+type Operation interface {
+	// Execute performs the request against the item with the given key, an index in [0, ItemCount)
+	Execute(ctx context.Context, key int) error
+}
+
+// NativeStatsReporter can be implemented by a Workload whose client holds native resources that
+// Go's memory statistics can't see. The counts are included in interval reports and the results.
+type NativeStatsReporter interface {
+	// NativeStats returns named counters, such as live handles or bytes held by native code
+	NativeStats() map[string]int64
+}
+
+// ItemKey identifies a benchmark item by ID and partition key
+type ItemKey struct {
+	ID           string
+	PartitionKey string
+}
+
+// NewItemKeys returns the keys of the items seeded by the createDb commands: item0..itemN-1,
+// spread round-robin across partition0..partitionP-1. Building them up front keeps string
+// formatting out of the measured request path.
+func NewItemKeys(itemCount, partitionCount int) []ItemKey {
+	keys := make([]ItemKey, itemCount)
+	for i := range keys {
+		keys[i] = ItemKey{
+			ID:           fmt.Sprintf("item%d", i),
+			PartitionKey: fmt.Sprintf("partition%d", i%partitionCount),
+		}
+	}
+	return keys
+}
+
+// RandomString returns size random letters and digits drawn from r. The createDb commands use it
+// for seeded documents and ItemMix for written ones, so both carry the same kind of data.
+func RandomString(r *rand.Rand, size int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+	return string(b)
+}
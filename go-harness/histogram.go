@@ -0,0 +1,125 @@
+package harness
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Histogram buckets use HDR-style log-linear spacing: values below histogramSubBuckets are counted
+// exactly, and every power of two above that is split into histogramSubBuckets/2 linear buckets.
+// A bucket's upper bound is then less than 1/128 (0.78%) above any value counted in it, which keeps
+// the relative error of reported percentiles under 1%.
+const (
+	histogramSubBucketBits = 8
+	histogramSubBuckets    = 1 << histogramSubBucketBits
+	histogramHalfBuckets   = histogramSubBuckets / 2
+	histogramBucketCount   = histogramSubBuckets + (63-histogramSubBucketBits)*histogramHalfBuckets
+)
+
+// Histogram records latencies with bounded relative error in constant memory.
+// It is not safe for concurrent use; give each worker its own and Merge them at the end.
+type Histogram struct {
+	counts [histogramBucketCount]int64
+	count  int64
+	sum    int64
+	min    int64
+	max    int64
+}
+
+// NewHistogram creates an empty histogram
+func NewHistogram() *Histogram {
+	return &Histogram{min: math.MaxInt64}
+}
+
+// Record adds a single latency to the histogram
+func (h *Histogram) Record(d time.Duration) {
+	v := max(int64(d), 0)
+	h.counts[histogramBucketIndex(v)]++
+	h.count++
+	h.sum += v
+	h.min = min(h.min, v)
+	h.max = max(h.max, v)
+}
+
+// Reset discards every recorded value
+func (h *Histogram) Reset() {
+	*h = Histogram{min: math.MaxInt64}
+}
+
+// Merge adds every value recorded in other to h
+func (h *Histogram) Merge(other *Histogram) {
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.count += other.count
+	h.sum += other.sum
+	h.min = min(h.min, other.min)
+	h.max = max(h.max, other.max)
+}
+
+// Count returns the number of recorded values
+func (h *Histogram) Count() int64 {
+	return h.count
+}
+
+// Mean returns the exact mean of the recorded values
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / h.count)
+}
+
+// Min returns the smallest recorded value
+func (h *Histogram) Min() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return time.Duration(h.min)
+}
+
+// Max returns the largest recorded value
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max)
+}
+
+// Percentile returns the value at or below which p percent (0-100) of the recorded values fall.
+// The result is the upper bound of the bucket containing that value, capped at the recorded maximum.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	target := int64(math.Ceil(p / 100 * float64(h.count)))
+	target = min(max(target, 1), h.count)
+
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= target {
+			return time.Duration(min(histogramBucketUpperBound(i), h.max))
+		}
+	}
+	return time.Duration(h.max)
+}
+
+// histogramBucketIndex returns the bucket that v (which must not be negative) is counted in
+func histogramBucketIndex(v int64) int {
+	if v < histogramSubBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - histogramSubBucketBits
+	top := int(v >> shift) // in [histogramHalfBuckets, histogramSubBuckets)
+	return histogramSubBuckets + (shift-1)*histogramHalfBuckets + top - histogramHalfBuckets
+}
+
+// histogramBucketUpperBound returns the largest value counted in bucket i
+func histogramBucketUpperBound(i int) int64 {
+	if i < histogramSubBuckets {
+		return int64(i)
+	}
+	shift := (i-histogramSubBuckets)/histogramHalfBuckets + 1
+	top := int64((i-histogramSubBuckets)%histogramHalfBuckets + histogramHalfBuckets)
+	return (top+1)<<shift - 1
+}
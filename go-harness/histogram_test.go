@@ -0,0 +1,76 @@
+package harness
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestHistogramBucketsCoverEveryValue(t *testing.T) {
+	for v := int64(0); v < 1<<16; v++ {
+		i := histogramBucketIndex(v)
+		if upper := histogramBucketUpperBound(i); upper < v {
+			t.Fatalf("value %d counted in bucket %d with upper bound %d", v, i, upper)
+		}
+		if i > 0 && histogramBucketUpperBound(i-1) >= v {
+			t.Fatalf("value %d also fits in bucket %d", v, i-1)
+		}
+		if upper := histogramBucketUpperBound(i); v > 0 && float64(upper-v)/float64(v) >= 0.01 {
+			t.Fatalf("value %d reported as %d, more than 1%% too high", v, upper)
+		}
+	}
+
+	if i := histogramBucketIndex(math.MaxInt64); i != histogramBucketCount-1 {
+		t.Fatalf("expected max value in last bucket %d, got %d", histogramBucketCount-1, i)
+	}
+}
+
+func TestHistogramPercentilesWithinOnePercent(t *testing.T) {
+	localRand := rand.New(rand.NewSource(1))
+	h := NewHistogram()
+	values := make([]int, 100000)
+	for i := range values {
+		values[i] = 100_000 + localRand.Intn(50_000_000)
+		h.Record(time.Duration(values[i]))
+	}
+	sort.Ints(values)
+
+	for _, p := range []float64{50, 90, 99, 99.9} {
+		exact := float64(values[int(math.Ceil(p/100*float64(len(values))))-1])
+		got := float64(h.Percentile(p))
+		if math.Abs(got-exact)/exact > 0.01 {
+			t.Errorf("p%v: expected %v within 1%%, got %v", p, exact, got)
+		}
+	}
+
+	if h.Max() != time.Duration(values[len(values)-1]) {
+		t.Errorf("expected max %v, got %v", values[len(values)-1], h.Max())
+	}
+	if h.Percentile(100) != h.Max() {
+		t.Errorf("expected p100 to equal max %v, got %v", h.Max(), h.Percentile(100))
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a, b := NewHistogram(), NewHistogram()
+	a.Record(1 * time.Millisecond)
+	a.Record(3 * time.Millisecond)
+	b.Record(5 * time.Millisecond)
+
+	merged := NewHistogram()
+	merged.Merge(a)
+	merged.Merge(b)
+	merged.Merge(NewHistogram())
+
+	if merged.Count() != 3 {
+		t.Errorf("expected 3 values, got %d", merged.Count())
+	}
+	if merged.Mean() != 3*time.Millisecond {
+		t.Errorf("expected mean 3ms, got %v", merged.Mean())
+	}
+	if merged.Min() != 1*time.Millisecond || merged.Max() != 5*time.Millisecond {
+		t.Errorf("expected range 1ms-5ms, got %v-%v", merged.Min(), merged.Max())
+	}
+}
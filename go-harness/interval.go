@@ -0,0 +1,229 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Phases of a run, as reported in IntervalReport
+const (
+	PhaseWarmup  = "warmup"
+	PhaseMeasure = "measure"
+)
+
+// IntervalReport holds the measurements for one reporting interval. A run with a report file
+// writes one per line as JSON.
+type IntervalReport struct {
+	Timestamp time.Time `json:"timestamp"`
+	Phase     string    `json:"phase"`
+	Workers   int       `json:"workers"`
+	// ElapsedMs is the time since the phase started, at the end of the interval
+	ElapsedMs    float64 `json:"elapsedMs"`
+	IntervalMs   float64 `json:"intervalMs"`
+	Ops          int     `json:"ops"`
+	OpsPerSecond float64 `json:"opsPerSecond"`
+	LatencyMs    float64 `json:"latencyMs"`
+	P50Ms        float64 `json:"p50Ms"`
+	P90Ms        float64 `json:"p90Ms"`
+	P99Ms        float64 `json:"p99Ms"`
+	MaxMs        float64 `json:"maxMs"`
+	Errors       int     `json:"errors"`
+	LateOps      int     `json:"lateOps"`
+	DroppedOps   int     `json:"droppedOps"`
+
+	// Go runtime memory at the end of the interval, and garbage collection during it
+	HeapBytes uint64  `json:"heapBytes"`
+	GCCount   uint32  `json:"gcCount"`
+	GCPauseMs float64 `json:"gcPauseMs"`
+
+	// NativeStats holds the counters of a NativeStatsReporter workload at the end of the interval
+	NativeStats map[string]int64 `json:"nativeStats,omitempty"`
+}
+
+// intervalStats is a worker's measurements for the current reporting interval. Workers record
+// into it under a lock, since the reporter swaps it out concurrently.
+type intervalStats struct {
+	mu        sync.Mutex
+	latencies *Histogram
+	errors    int
+}
+
+func newIntervalStats() *intervalStats {
+	return &intervalStats{latencies: NewHistogram()}
+}
+
+func (s *intervalStats) recordSuccess(latency time.Duration) {
+	s.mu.Lock()
+	s.latencies.Record(latency)
+	s.mu.Unlock()
+}
+
+func (s *intervalStats) recordError() {
+	s.mu.Lock()
+	s.errors++
+	s.mu.Unlock()
+}
+
+// drain adds the interval's measurements to latencies and returns its error count, then resets it
+func (s *intervalStats) drain(latencies *Histogram) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latencies.Merge(s.latencies)
+	s.latencies.Reset()
+	errors := s.errors
+	s.errors = 0
+	return errors
+}
+
+// intervalReporter periodically prints, and optionally writes, the measurements of one phase
+type intervalReporter struct {
+	state    *runState
+	phase    string
+	workers  []*worker
+	start    time.Time
+	end      time.Time
+	openLoop bool
+
+	// Values at the end of the previous interval, to report deltas
+	last      time.Time
+	lastLate  int64
+	lastDrop  int64
+	lastGC    uint32
+	lastPause uint64
+	latencies *Histogram
+}
+
+func newIntervalReporter(state *runState, phase string, workers []*worker, start time.Time, duration time.Duration, openLoop bool) *intervalReporter {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return &intervalReporter{
+		state:     state,
+		phase:     phase,
+		workers:   workers,
+		start:     start,
+		end:       start.Add(duration),
+		openLoop:  openLoop,
+		last:      start,
+		lastLate:  atomic.LoadInt64(&state.lateOps),
+		lastDrop:  atomic.LoadInt64(&state.droppedOps),
+		lastGC:    mem.NumGC,
+		lastPause: mem.PauseTotalNs,
+		latencies: NewHistogram(),
+	}
+}
+
+// run reports every interval until done is closed
+func (r *intervalReporter) run(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			r.report(now, false)
+		case <-done:
+			return
+		}
+	}
+}
+
+// flush reports the final, partial interval of the phase if anything happened during it
+func (r *intervalReporter) flush(now time.Time) {
+	r.report(now, true)
+}
+
+// report collects the measurements since the previous report from every worker and emits them
+func (r *intervalReporter) report(now time.Time, final bool) {
+	r.latencies.Reset()
+	errors := 0
+	for _, w := range r.workers {
+		errors += w.interval.drain(r.latencies)
+	}
+	if final && r.latencies.Count() == 0 && errors == 0 {
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	late := atomic.LoadInt64(&r.state.lateOps)
+	dropped := atomic.LoadInt64(&r.state.droppedOps)
+	elapsed := now.Sub(r.last)
+	opsPerSecond := 0.0
+	if elapsed > 0 {
+		// A report right after the previous one, e.g. the final flush, would otherwise divide by zero
+		opsPerSecond = float64(r.latencies.Count()) / elapsed.Seconds()
+	}
+
+	report := IntervalReport{
+		Timestamp:    now.UTC(),
+		Phase:        r.phase,
+		Workers:      len(r.workers),
+		ElapsedMs:    durationMs(now.Sub(r.start)),
+		IntervalMs:   durationMs(elapsed),
+		Ops:          int(r.latencies.Count()),
+		OpsPerSecond: opsPerSecond,
+		LatencyMs:    durationMs(r.latencies.Mean()),
+		P50Ms:        durationMs(r.latencies.Percentile(50)),
+		P90Ms:        durationMs(r.latencies.Percentile(90)),
+		P99Ms:        durationMs(r.latencies.Percentile(99)),
+		MaxMs:        durationMs(r.latencies.Max()),
+		Errors:       errors,
+		LateOps:      int(late - r.lastLate),
+		DroppedOps:   int(dropped - r.lastDrop),
+		HeapBytes:    mem.HeapAlloc,
+		GCCount:      mem.NumGC - r.lastGC,
+		GCPauseMs:    durationMs(time.Duration(mem.PauseTotalNs - r.lastPause)),
+		NativeStats:  r.state.snapshotNativeStats(),
+	}
+
+	r.last, r.lastLate, r.lastDrop, r.lastGC, r.lastPause = now, late, dropped, mem.NumGC, mem.PauseTotalNs
+
+	r.print(&report, r.end.Sub(now))
+	if r.state.intervalOut != nil {
+		r.state.intervalOut.write(&report)
+	}
+}
+
+func (r *intervalReporter) print(report *IntervalReport, remaining time.Duration) {
+	prefix := ""
+	if r.phase == PhaseWarmup {
+		prefix = "warmup "
+	}
+	openLoop := ""
+	if r.openLoop {
+		openLoop = fmt.Sprintf(", %d late, %d dropped", report.LateOps, report.DroppedOps)
+	}
+	fmt.Printf("[%s%v] %.1f ops/sec, p50/p99/max %.2f/%.2f/%.2f ms, %d errors%s, heap %.1f MB, %d GCs (%.2f ms), %v remaining\n",
+		prefix,
+		time.Duration(report.ElapsedMs*float64(time.Millisecond)).Round(100*time.Millisecond),
+		report.OpsPerSecond,
+		report.P50Ms, report.P99Ms, report.MaxMs,
+		report.Errors,
+		openLoop,
+		megabytes(report.HeapBytes),
+		report.GCCount, report.GCPauseMs,
+		max(remaining, 0).Round(100*time.Millisecond))
+}
+
+// intervalWriter writes interval reports as JSON lines
+type intervalWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newIntervalWriter(w io.Writer) *intervalWriter {
+	return &intervalWriter{encoder: json.NewEncoder(w)}
+}
+
+func (w *intervalWriter) write(report *IntervalReport) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.encoder.Encode(report); err != nil {
+		fmt.Printf("Failed to write interval report: %v\n", err)
+	}
+}
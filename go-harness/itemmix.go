@@ -0,0 +1,213 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// Operations of the item mix run by the benchmark CLIs' mixed commands
+const (
+	ItemRead    = "read"
+	ItemCreate  = "create"
+	ItemUpsert  = "upsert"
+	ItemReplace = "replace"
+	ItemDelete  = "delete"
+	ItemQuery   = "query"
+)
+
+// DefaultItemMix is the default --mix of the mixed commands
+const DefaultItemMix = "read=70,create=10,upsert=5,replace=5,delete=5,query=5"
+
+// maxCreatedItems bounds how many created items each worker remembers for its deletes. Once it is
+// full, creates push out the oldest, which are left in the container like any other undeleted item.
+const maxCreatedItems = 1000
+
+// itemMixSeedOffset separates the seeds of the item mix's worker sources from the runner's
+const itemMixSeedOffset = 0x5DEECE66D
+
+// ItemQueryText looks up a single item, so queries return the same data as point reads
+const ItemQueryText = "SELECT * FROM c WHERE c.id = @id"
+
+// ItemClient performs the item mix's operations with one SDK. Documents are JSON.
+type ItemClient interface {
+	ReadItem(ctx context.Context, item ItemKey) error
+	CreateItem(ctx context.Context, item ItemKey, document []byte) error
+	UpsertItem(ctx context.Context, item ItemKey, document []byte) error
+	ReplaceItem(ctx context.Context, item ItemKey, document []byte) error
+	DeleteItem(ctx context.Context, item ItemKey) error
+	// QueryItem runs ItemQueryText with @id set to item.ID in item's partition, reading every page
+	QueryItem(ctx context.Context, item ItemKey) error
+}
+
+// ParseItemMix parses a --mix spec for an ItemMix. Deletes only remove items created in the same
+// run, so they can't outnumber creates.
+func ParseItemMix(spec string) ([]WeightedOperation, error) {
+	ops, err := ParseMix(spec, ItemRead, ItemCreate, ItemUpsert, ItemReplace, ItemDelete, ItemQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := map[string]float64{}
+	for _, op := range ops {
+		weights[op.Name] = op.Weight
+	}
+	if weights[ItemDelete] > weights[ItemCreate] {
+		return nil, fmt.Errorf("the delete weight must not exceed the create weight, since deletes only remove items created during the run")
+	}
+	return ops, nil
+}
+
+// ItemMix is a MixedWorkload that runs a weighted mix of item operations through an ItemClient.
+// Reads, upserts, replaces and queries target the seeded items, and writes keep each item's id
+// and partition key so reads never miss. Creates add items with run-specific ids, and deletes
+// remove items the same worker created earlier.
+type ItemMix struct {
+	Client         ItemClient
+	Ops            []WeightedOperation
+	Keys           []ItemKey
+	PartitionCount int
+	DocumentSize   int
+
+	// Seed seeds each worker's document data, so runs with the same --seed write the same documents
+	Seed int64
+
+	// RunID prefixes the ids of created items so runs never collide with each other or with seeded items
+	RunID string
+}
+
+// NewItemMix returns an ItemMix of ops over the items seeded for cfg, writing documents with
+// documentSize bytes of random data
+func NewItemMix(client ItemClient, cfg *Config, ops []WeightedOperation, documentSize int) *ItemMix {
+	return &ItemMix{
+		Client:         client,
+		Ops:            ops,
+		Keys:           NewItemKeys(cfg.ItemCount, cfg.PartitionCount),
+		PartitionCount: cfg.PartitionCount,
+		DocumentSize:   documentSize,
+		Seed:           cfg.Seed,
+		RunID:          "mixed-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+func (m *ItemMix) Name() string {
+	return "mixed"
+}
+
+func (m *ItemMix) Operations() []WeightedOperation {
+	return m.Ops
+}
+
+func (m *ItemMix) NewWorker(workerID int) (Operation, error) {
+	// Seeded like the runner's worker sources, but offset so the two don't produce the same sequence
+	localRand := rand.New(rand.NewSource((m.Seed ^ itemMixSeedOffset) + int64(workerID) + 1))
+	return &itemMixWorker{
+		mix:       m,
+		id:        workerID,
+		localRand: localRand,
+		// Generate the document data once per worker to keep it out of the measured path
+		data: RandomString(localRand, m.DocumentSize),
+	}, nil
+}
+
+// itemMixWorker tracks the items it has created so its deletes have something to remove
+type itemMixWorker struct {
+	mix       *ItemMix
+	id        int
+	localRand *rand.Rand
+	data      string
+	created   createdItems
+	sequence  int
+}
+
+// Execute performs a point read, whatever the mix. The runner calls ExecuteOperation for mixed workloads.
+func (w *itemMixWorker) Execute(ctx context.Context, key int) error {
+	return w.mix.Client.ReadItem(ctx, w.mix.Keys[key])
+}
+
+func (w *itemMixWorker) ExecuteOperation(ctx context.Context, kind, key int) error {
+	client := w.mix.Client
+	item := w.mix.Keys[key]
+
+	switch w.mix.Ops[kind].Name {
+	case ItemRead:
+		return client.ReadItem(ctx, item)
+	case ItemUpsert:
+		return client.UpsertItem(ctx, item, w.document(item))
+	case ItemReplace:
+		return client.ReplaceItem(ctx, item, w.document(item))
+	case ItemQuery:
+		return client.QueryItem(ctx, item)
+	case ItemCreate:
+		newItem := ItemKey{
+			ID:           fmt.Sprintf("%s-%d-%d", w.mix.RunID, w.id, w.sequence),
+			PartitionKey: fmt.Sprintf("partition%d", w.sequence%w.mix.PartitionCount),
+		}
+		w.sequence++
+		err := client.CreateItem(ctx, newItem, w.document(newItem))
+		if err == nil {
+			w.created.push(newItem)
+		}
+		return err
+	case ItemDelete:
+		target, ok := w.created.pop()
+		if !ok {
+			return ErrSkipped
+		}
+		return client.DeleteItem(ctx, target)
+	}
+	return fmt.Errorf("unknown operation %q", w.mix.Ops[kind].Name)
+}
+
+// createdItems is a ring buffer of up to maxCreatedItems keys, oldest first
+type createdItems struct {
+	ring  []ItemKey
+	head  int
+	count int
+}
+
+// push adds item, dropping the oldest item if the buffer is full
+func (c *createdItems) push(item ItemKey) {
+	if c.ring == nil {
+		c.ring = make([]ItemKey, maxCreatedItems)
+	}
+	if c.count == len(c.ring) {
+		c.head = (c.head + 1) % len(c.ring)
+		c.count--
+	}
+	c.ring[(c.head+c.count)%len(c.ring)] = item
+	c.count++
+}
+
+// pop removes and returns the oldest item
+func (c *createdItems) pop() (ItemKey, bool) {
+	if c.count == 0 {
+		return ItemKey{}, false
+	}
+	item := c.ring[c.head]
+	c.head = (c.head + 1) % len(c.ring)
+	c.count--
+	return item, true
+}
+
+// itemDocument is the JSON of the items seeded by the createDb commands
+type itemDocument struct {
+	ID           string `json:"id"`
+	PartitionKey string `json:"partitionKey"`
+	Data         string `json:"data"`
+	RandomNumber int    `json:"randomNumber"`
+}
+
+// document returns the JSON for a write to item, with a fresh random number so every write changes it
+func (w *itemMixWorker) document(item ItemKey) []byte {
+	doc, _ := json.Marshal(itemDocument{
+		ID:           item.ID,
+		PartitionKey: item.PartitionKey,
+		Data:         w.data,
+		RandomNumber: w.localRand.Intn(10001),
+	})
+	return doc
+}
@@ -0,0 +1,122 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCreatedItems(t *testing.T) {
+	var created createdItems
+	if _, ok := created.pop(); ok {
+		t.Fatal("expected nothing to pop from an empty buffer")
+	}
+
+	// Overfill the buffer so the oldest items are dropped and the ring wraps around
+	total := maxCreatedItems + 10
+	for i := range total {
+		created.push(ItemKey{ID: fmt.Sprintf("item%d", i)})
+	}
+	if len(created.ring) != maxCreatedItems {
+		t.Fatalf("expected the buffer to stay at %d items, got %d", maxCreatedItems, len(created.ring))
+	}
+	for i := total - maxCreatedItems; i < total; i++ {
+		item, ok := created.pop()
+		if want := fmt.Sprintf("item%d", i); !ok || item.ID != want {
+			t.Fatalf("expected %s, got %q (ok %v)", want, item.ID, ok)
+		}
+	}
+	if _, ok := created.pop(); ok {
+		t.Fatal("expected the buffer to be empty")
+	}
+}
+
+// recordingItems records every item operation instead of performing it
+type recordingItems struct {
+	calls []string
+}
+
+func (c *recordingItems) record(op string, item ItemKey, document []byte) error {
+	c.calls = append(c.calls, fmt.Sprintf("%s %s/%s %s", op, item.PartitionKey, item.ID, document))
+	return nil
+}
+
+func (c *recordingItems) ReadItem(ctx context.Context, item ItemKey) error {
+	return c.record(ItemRead, item, nil)
+}
+
+func (c *recordingItems) CreateItem(ctx context.Context, item ItemKey, document []byte) error {
+	return c.record(ItemCreate, item, document)
+}
+
+func (c *recordingItems) UpsertItem(ctx context.Context, item ItemKey, document []byte) error {
+	return c.record(ItemUpsert, item, document)
+}
+
+func (c *recordingItems) ReplaceItem(ctx context.Context, item ItemKey, document []byte) error {
+	return c.record(ItemReplace, item, document)
+}
+
+func (c *recordingItems) DeleteItem(ctx context.Context, item ItemKey) error {
+	return c.record(ItemDelete, item, nil)
+}
+
+func (c *recordingItems) QueryItem(ctx context.Context, item ItemKey) error {
+	return c.record(ItemQuery, item, nil)
+}
+
+func TestItemMixSeed(t *testing.T) {
+	ops, err := ParseItemMix(DefaultItemMix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(seed int64) []string {
+		cfg := &Config{ItemCount: 100, PartitionCount: 4, Workers: 1, Duration: 50 * time.Millisecond, Seed: seed}
+		client := &recordingItems{}
+		mix := NewItemMix(client, cfg, ops, 16)
+		mix.RunID = "test"
+		if _, err := Run(context.Background(), cfg, mix); err != nil {
+			t.Fatal(err)
+		}
+		return client.calls
+	}
+
+	// Runs last a fixed time rather than a fixed number of operations, so compare what both ran
+	first, second, other := run(42), run(42), run(43)
+	n := min(len(first), len(second), len(other))
+	if n < 100 {
+		t.Fatalf("expected at least 100 operations per run, got %d", n)
+	}
+	if !slices.Equal(first[:n], second[:n]) {
+		t.Error("expected runs with the same seed to perform the same operations")
+	}
+	if slices.Equal(first[:n], other[:n]) {
+		t.Error("expected runs with different seeds to perform different operations")
+	}
+}
+
+func TestItemMixExecute(t *testing.T) {
+	ops, err := ParseItemMix("create=1,read=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &recordingItems{}
+	mix := NewItemMix(client, &Config{ItemCount: 10, PartitionCount: 2}, ops, 16)
+	worker, err := mix.NewWorker(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.Execute(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// Execute reads even though the mix starts with creates
+	want := fmt.Sprintf("%s %s/%s ", ItemRead, mix.Keys[3].PartitionKey, mix.Keys[3].ID)
+	if !slices.Equal(client.calls, []string{want}) {
+		t.Errorf("expected %q, got %q", want, client.calls)
+	}
+}
@@ -0,0 +1,117 @@
+package harness
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// IterationResults summarizes a run of several iterations, to show how much the results vary
+// between them
+type IterationResults struct {
+	Count    int           `json:"count"`
+	Cooldown time.Duration `json:"cooldown"`
+
+	OpsPerSecond Statistic `json:"opsPerSecond"`
+	LatencyMs    Statistic `json:"latencyMs"`
+	P50Ms        Statistic `json:"p50Ms"`
+	P90Ms        Statistic `json:"p90Ms"`
+	P99Ms        Statistic `json:"p99Ms"`
+	P999Ms       Statistic `json:"p999Ms"`
+
+	Runs []*IterationRun `json:"runs"`
+}
+
+// IterationRun summarizes one iteration
+type IterationRun struct {
+	Iteration   int           `json:"iteration"`
+	ElapsedTime time.Duration `json:"elapsedTime"`
+	Summary
+}
+
+// Statistic describes a metric measured once per iteration
+type Statistic struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	// CI95 is the half-width of the 95% confidence interval for the mean, from Student's t-distribution
+	CI95 float64 `json:"ci95"`
+}
+
+func newIterationResults(runs []*IterationRun, cooldown time.Duration) *IterationResults {
+	metric := func(value func(*IterationRun) float64) Statistic {
+		values := make([]float64, len(runs))
+		for i, run := range runs {
+			values[i] = value(run)
+		}
+		return newStatistic(values)
+	}
+
+	return &IterationResults{
+		Count:        len(runs),
+		Cooldown:     cooldown,
+		OpsPerSecond: metric(func(r *IterationRun) float64 { return r.OpsPerSecond }),
+		LatencyMs:    metric(func(r *IterationRun) float64 { return r.LatencyMs }),
+		P50Ms:        metric(func(r *IterationRun) float64 { return r.P50Ms }),
+		P90Ms:        metric(func(r *IterationRun) float64 { return r.P90Ms }),
+		P99Ms:        metric(func(r *IterationRun) float64 { return r.P99Ms }),
+		P999Ms:       metric(func(r *IterationRun) float64 { return r.P999Ms }),
+		Runs:         runs,
+	}
+}
+
+// newStatistic computes the mean, sample standard deviation and confidence interval of values
+func newStatistic(values []float64) Statistic {
+	if len(values) == 0 {
+		return Statistic{}
+	}
+
+	s := Statistic{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, v := range values {
+		s.Mean += v
+		s.Min = min(s.Min, v)
+		s.Max = max(s.Max, v)
+	}
+	s.Mean /= float64(len(values))
+
+	if len(values) > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - s.Mean) * (v - s.Mean)
+		}
+		s.StdDev = math.Sqrt(squares / float64(len(values)-1))
+		s.CI95 = tCritical95(len(values)-1) * s.StdDev / math.Sqrt(float64(len(values)))
+	}
+	return s
+}
+
+// String formats the statistic as "mean ± ci95"
+func (s Statistic) String() string {
+	return fmt.Sprintf("%.2f ± %.2f", s.Mean, s.CI95)
+}
+
+// tCriticalValues are the two-sided 95% critical values of Student's t-distribution for 1 to 30
+// degrees of freedom
+var tCriticalValues = [...]float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tCritical95 returns the two-sided 95% critical value of Student's t-distribution. Beyond the
+// table it rounds down to the next tabulated degrees of freedom, which widens the interval slightly.
+func tCritical95(degreesOfFreedom int) float64 {
+	switch {
+	case degreesOfFreedom <= len(tCriticalValues):
+		return tCriticalValues[degreesOfFreedom-1]
+	case degreesOfFreedom < 40:
+		return tCriticalValues[len(tCriticalValues)-1]
+	case degreesOfFreedom < 60:
+		return 2.021 // 40 degrees of freedom
+	case degreesOfFreedom < 120:
+		return 2.000 // 60
+	default:
+		return 1.980 // 120
+	}
+}
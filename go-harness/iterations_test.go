@@ -0,0 +1,36 @@
+package harness
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewStatistic(t *testing.T) {
+	s := newStatistic([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+
+	if s.Mean != 5 || s.Min != 2 || s.Max != 9 {
+		t.Errorf("unexpected mean/min/max %+v", s)
+	}
+	if math.Abs(s.StdDev-2.138) > 0.001 {
+		t.Errorf("expected sample stddev 2.138, got %v", s.StdDev)
+	}
+	// t(0.975, 7) = 2.365
+	if math.Abs(s.CI95-2.365*s.StdDev/math.Sqrt(8)) > 1e-9 {
+		t.Errorf("unexpected confidence interval %v", s.CI95)
+	}
+
+	if single := newStatistic([]float64{3}); single.Mean != 3 || single.StdDev != 0 || single.CI95 != 0 {
+		t.Errorf("expected a single value to have no spread, got %+v", single)
+	}
+}
+
+func TestTCritical95(t *testing.T) {
+	for _, tc := range []struct {
+		df   int
+		want float64
+	}{{1, 12.706}, {30, 2.042}, {35, 2.042}, {45, 2.021}, {1000, 1.980}} {
+		if got := tCritical95(tc.df); got != tc.want {
+			t.Errorf("tCritical95(%d) = %v, expected %v", tc.df, got, tc.want)
+		}
+	}
+}
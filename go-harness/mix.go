@@ -0,0 +1,124 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// ErrSkipped is returned by MixedOperation.ExecuteOperation when the chosen operation can't run
+// right now, e.g. a delete with nothing left to delete. The runner picks another operation.
+var ErrSkipped = errors.New("operation skipped")
+
+// maxSkips bounds how many times in a row the runner re-picks after ErrSkipped before counting
+// the operation as failed
+const maxSkips = 100
+
+// WeightedOperation names one kind of operation in a mixed workload and its share of the traffic
+type WeightedOperation struct {
+	Name   string
+	Weight float64
+}
+
+// MixedWorkload is a Workload that executes several kinds of operation in fixed proportions.
+// The runner picks the kind of each operation and reports latency statistics per kind. The
+// Operations returned by NewWorker must also implement MixedOperation; Run calls ExecuteOperation
+// instead of Execute.
+type MixedWorkload interface {
+	Workload
+	// Operations returns the kinds of operation to run and their weights
+	Operations() []WeightedOperation
+}
+
+// MixedOperation executes the operations of a MixedWorkload
+type MixedOperation interface {
+	// ExecuteOperation performs the operation at index kind in Operations() against the item with
+	// the given key
+	ExecuteOperation(ctx context.Context, kind, key int) error
+}
+
+// ParseMix parses a comma-separated list of name=weight pairs, e.g. "read=80,create=10,delete=10".
+// Names must be in allowed, and weights are relative so they don't need to add up to 100.
+func ParseMix(spec string, allowed ...string) ([]WeightedOperation, error) {
+	var ops []WeightedOperation
+	seen := map[string]bool{}
+	for _, field := range strings.Split(spec, ",") {
+		name, weightText, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry %q, expected name=weight", field)
+		}
+		weight, err := strconv.ParseFloat(weightText, 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s, expected a non-negative number", weightText, name)
+		}
+
+		known := false
+		for _, a := range allowed {
+			known = known || a == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown operation %q in mix, expected one of: %s", name, strings.Join(allowed, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("operation %q appears more than once in mix", name)
+		}
+		seen[name] = true
+
+		if weight > 0 {
+			ops = append(ops, WeightedOperation{Name: name, Weight: weight})
+		}
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("mix must give at least one operation a positive weight")
+	}
+	return ops, nil
+}
+
+// FormatMix renders a mix as percentages, e.g. "read=80%, delete=20%"
+func FormatMix(ops []WeightedOperation) string {
+	total := 0.0
+	for _, op := range ops {
+		total += op.Weight
+	}
+	parts := make([]string, len(ops))
+	for i, op := range ops {
+		parts[i] = fmt.Sprintf("%s=%.1f%%", op.Name, op.Weight/total*100)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mix picks operation kinds at random according to their weights
+type mix struct {
+	names      []string
+	cumulative []float64
+}
+
+func newMix(ops []WeightedOperation) (*mix, error) {
+	m := &mix{}
+	total := 0.0
+	for _, op := range ops {
+		if op.Weight < 0 {
+			return nil, fmt.Errorf("operation %s has negative weight %v", op.Name, op.Weight)
+		}
+		total += op.Weight
+		m.names = append(m.names, op.Name)
+		m.cumulative = append(m.cumulative, total)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("mixed workload has no operations with a positive weight")
+	}
+	return m, nil
+}
+
+func (m *mix) pick(r *rand.Rand) int {
+	target := r.Float64() * m.cumulative[len(m.cumulative)-1]
+	for i, c := range m.cumulative {
+		if target < c {
+			return i
+		}
+	}
+	return len(m.cumulative) - 1
+}
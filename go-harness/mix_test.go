@@ -0,0 +1,78 @@
+package harness
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// queueWorkload mixes "push" and "pop" operations, skipping pops when nothing has been pushed
+type queueWorkload struct {
+	pending atomic.Int64
+}
+
+func (w *queueWorkload) Name() string { return "queue" }
+
+func (w *queueWorkload) NewWorker(workerID int) (Operation, error) { return w, nil }
+
+func (w *queueWorkload) Operations() []WeightedOperation {
+	return []WeightedOperation{{Name: "push", Weight: 3}, {Name: "pop", Weight: 1}}
+}
+
+func (w *queueWorkload) Execute(ctx context.Context, key int) error {
+	return w.ExecuteOperation(ctx, 0, key)
+}
+
+func (w *queueWorkload) ExecuteOperation(ctx context.Context, kind, key int) error {
+	time.Sleep(100 * time.Microsecond)
+	if kind == 0 {
+		w.pending.Add(1)
+		return nil
+	}
+	if w.pending.Add(-1) < 0 {
+		w.pending.Add(1)
+		return ErrSkipped
+	}
+	return nil
+}
+
+func TestRunMixed(t *testing.T) {
+	workload := &queueWorkload{}
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 2, Duration: 200 * time.Millisecond}
+
+	results, err := Run(context.Background(), cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	push, pop := results.Operations["push"], results.Operations["pop"]
+	if push == nil || pop == nil {
+		t.Fatalf("expected per-operation results for push and pop, got %v", results.Operations)
+	}
+	if push.TotalOps+pop.TotalOps != results.TotalOps {
+		t.Errorf("per-operation totals %d + %d don't add up to %d", push.TotalOps, pop.TotalOps, results.TotalOps)
+	}
+	if ratio := float64(push.TotalOps) / float64(results.TotalOps); ratio < 0.6 || ratio > 0.9 {
+		t.Errorf("expected about 75%% pushes, got %.0f%%", ratio*100)
+	}
+	if results.Errors != 0 {
+		t.Errorf("skipped operations should not count as errors, got %d", results.Errors)
+	}
+}
+
+func TestParseMix(t *testing.T) {
+	ops, err := ParseMix("read=80, create=20,delete=0", "read", "create", "delete")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || ops[0] != (WeightedOperation{Name: "read", Weight: 80}) || ops[1].Name != "create" {
+		t.Errorf("unexpected mix %+v", ops)
+	}
+
+	for _, spec := range []string{"read", "read=-1", "write=1", "read=1,read=2", "read=0"} {
+		if _, err := ParseMix(spec, "read"); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package harness
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Output formats for benchmark results
+const (
+	OutputMarkdown = "markdown"
+	OutputJSON     = "json"
+	OutputCSV      = "csv"
+)
+
+// ReportSchemaVersion is bumped whenever a field in Report is renamed or removed, or the CSV
+// columns change
+const ReportSchemaVersion = 1
+
+// Report is the machine-readable record of a single benchmark run
+type Report struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Metadata      RunMetadata `json:"metadata"`
+	Results       *Results    `json:"results"`
+}
+
+// RunMetadata describes the environment and configuration a benchmark ran with
+type RunMetadata struct {
+	Implementation string            `json:"implementation"`
+	Operation      string            `json:"operation"`
+	Timestamp      time.Time         `json:"timestamp"`
+	GitSHA         string            `json:"gitSha"`
+	GoVersion      string            `json:"goVersion"`
+	GOMAXPROCS     int               `json:"gomaxprocs"`
+	NumCPU         int               `json:"numCpu"`
+	OS             string            `json:"os"`
+	Arch           string            `json:"arch"`
+	CPUModel       string            `json:"cpuModel"`
+	Flags          map[string]string `json:"flags"`
+}
+
+// OutputOptions selects how and where a Report is written
+type OutputOptions struct {
+	Format string
+	// File is the path to write to; empty means stdout
+	File string
+}
+
+// NewReport records results along with metadata about the current process and the flags cmd was run with
+func NewReport(cmd *cobra.Command, implementation, operation string, results *Results) *Report {
+	return &Report{
+		SchemaVersion: ReportSchemaVersion,
+		Metadata: RunMetadata{
+			Implementation: implementation,
+			Operation:      operation,
+			Timestamp:      time.Now().UTC(),
+			GitSHA:         gitSHA(),
+			GoVersion:      runtime.Version(),
+			GOMAXPROCS:     runtime.GOMAXPROCS(0),
+			NumCPU:         runtime.NumCPU(),
+			OS:             runtime.GOOS,
+			Arch:           runtime.GOARCH,
+			CPUModel:       cpuModel(),
+			Flags:          flagValues(cmd),
+		},
+		Results: results,
+	}
+}
+
+// WriteReport writes the report in the selected format to the output file, or stdout if none was given
+func WriteReport(opts OutputOptions, report *Report) error {
+	var w io.Writer = os.Stdout
+	if opts.File != "" {
+		f, err := os.Create(opts.File)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch opts.Format {
+	case OutputJSON:
+		err = writeJSONReport(bw, report)
+	case OutputCSV:
+		err = writeCSVReport(bw, report)
+	default:
+		// Frame the table when it is mixed in with the rest of the console output
+		if opts.File == "" {
+			fmt.Fprintf(bw, "\n=== Markdown Table (%s) ===\n", report.Metadata.Operation)
+		}
+		err = writeMarkdownReport(bw, report)
+		if opts.File == "" {
+			fmt.Fprintf(bw, "============================================\n")
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func writeJSONReport(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func writeMarkdownReport(w io.Writer, report *Report) error {
+	results := report.Results
+	fmt.Fprintf(w, "| Implementation | Total Ops | Duration (ms) | Ops/sec | Latency (ms) | p50 (ms) | p90 (ms) | p99 (ms) | p99.9 (ms) | Max (ms) |\n")
+	fmt.Fprintf(w, "|---------------|-----------|---------------|---------|--------------|----------|----------|----------|------------|----------|\n")
+	_, err := fmt.Fprintf(w, "| %s | %d | %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+		report.Metadata.Implementation,
+		results.TotalOps,
+		results.ElapsedTime.Milliseconds(),
+		results.OpsPerSecond,
+		results.LatencyMs,
+		results.P50Ms,
+		results.P90Ms,
+		results.P99Ms,
+		results.P999Ms,
+		results.MaxMs)
+	if err != nil {
+		return err
+	}
+
+	// Mixed workloads get a row per kind of operation, and ramp-up runs a row per step
+	for _, name := range results.OperationNames() {
+		if err := writeMarkdownRow(w, fmt.Sprintf("%s (%s)", report.Metadata.Implementation, name), results.ElapsedTime, results.Operations[name]); err != nil {
+			return err
+		}
+	}
+	for _, step := range results.Steps {
+		if err := writeMarkdownRow(w, fmt.Sprintf("%s (%d workers)", report.Metadata.Implementation, step.Workers), step.ElapsedTime, &step.Summary); err != nil {
+			return err
+		}
+	}
+
+	// Multi-iteration runs also get the mean and 95% confidence interval across iterations
+	if it := results.Iterations; it != nil {
+		_, err := fmt.Fprintf(w, "| %s (mean of %d) | %d | %d | %s | %s | %s | %s | %s | %s | - |\n",
+			report.Metadata.Implementation,
+			it.Count,
+			results.TotalOps/it.Count,
+			results.ElapsedTime.Milliseconds()/int64(it.Count),
+			it.OpsPerSecond,
+			it.LatencyMs,
+			it.P50Ms,
+			it.P90Ms,
+			it.P99Ms,
+			it.P999Ms)
+		return err
+	}
+	return nil
+}
+
+// writeMarkdownRow writes a table row for a Summary
+func writeMarkdownRow(w io.Writer, label string, elapsed time.Duration, summary *Summary) error {
+	_, err := fmt.Fprintf(w, "| %s | %d | %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+		label,
+		summary.TotalOps,
+		elapsed.Milliseconds(),
+		summary.OpsPerSecond,
+		summary.LatencyMs,
+		summary.P50Ms,
+		summary.P90Ms,
+		summary.P99Ms,
+		summary.P999Ms,
+		summary.MaxMs)
+	return err
+}
+
+// csvColumns are the Results columns of CSV reports, after the metadata columns. Columns are named
+// after the JSON field and only change along with ReportSchemaVersion.
+var csvColumns = []struct {
+	name  string
+	value func(r *Results) string
+}{
+	{"totalOps", func(r *Results) string { return strconv.Itoa(r.TotalOps) }},
+	{"elapsedTime", func(r *Results) string { return strconv.FormatInt(int64(r.ElapsedTime), 10) }},
+	{"opsPerSecond", func(r *Results) string { return formatCSVFloat(r.OpsPerSecond) }},
+	{"latencyMs", func(r *Results) string { return formatCSVFloat(r.LatencyMs) }},
+	{"p50Ms", func(r *Results) string { return formatCSVFloat(r.P50Ms) }},
+	{"p90Ms", func(r *Results) string { return formatCSVFloat(r.P90Ms) }},
+	{"p99Ms", func(r *Results) string { return formatCSVFloat(r.P99Ms) }},
+	{"p999Ms", func(r *Results) string { return formatCSVFloat(r.P999Ms) }},
+	{"maxMs", func(r *Results) string { return formatCSVFloat(r.MaxMs) }},
+	{"allocsPerOp", func(r *Results) string { return formatCSVFloat(r.AllocsPerOp) }},
+	{"bytesPerOp", func(r *Results) string { return formatCSVFloat(r.BytesPerOp) }},
+	{"errors", func(r *Results) string { return strconv.Itoa(r.Errors) }},
+	{"errorRate", func(r *Results) string { return formatCSVFloat(r.ErrorRate) }},
+	{"errorsByClass", func(r *Results) string { return formatCSVCounts(r.ErrorsByClass) }},
+	{"errorLatencyMs", func(r *Results) string { return formatCSVFloat(r.ErrorLatencyMs) }},
+	{"errorP99Ms", func(r *Results) string { return formatCSVFloat(r.ErrorP99Ms) }},
+	{"operations", func(r *Results) string { return formatCSVJSON(len(r.Operations) > 0, r.Operations) }},
+	{"steps", func(r *Results) string { return formatCSVJSON(len(r.Steps) > 0, r.Steps) }},
+	{"process", func(r *Results) string { return formatCSVJSON(r.Process != nil, r.Process) }},
+	{"nativeStats", func(r *Results) string { return formatCSVCounts(r.NativeStats) }},
+	{"iterations", func(r *Results) string { return formatCSVJSON(r.Iterations != nil, r.Iterations) }},
+	{"targetRate", func(r *Results) string { return formatCSVFloat(r.TargetRate) }},
+	{"lateOps", func(r *Results) string { return strconv.Itoa(r.LateOps) }},
+	{"droppedOps", func(r *Results) string { return strconv.Itoa(r.DroppedOps) }},
+	{"seed", func(r *Results) string { return strconv.FormatInt(r.Seed, 10) }},
+}
+
+// writeCSVReport writes a header and a single row. Metadata columns come first, followed by
+// csvColumns.
+func writeCSVReport(w io.Writer, report *Report) error {
+	m := report.Metadata
+	header := []string{"schemaVersion", "implementation", "operation", "timestamp", "gitSha", "goVersion", "gomaxprocs", "numCpu", "os", "arch", "cpuModel", "flags"}
+	row := []string{
+		strconv.Itoa(report.SchemaVersion),
+		m.Implementation,
+		m.Operation,
+		m.Timestamp.Format(time.RFC3339),
+		m.GitSHA,
+		m.GoVersion,
+		strconv.Itoa(m.GOMAXPROCS),
+		strconv.Itoa(m.NumCPU),
+		m.OS,
+		m.Arch,
+		m.CPUModel,
+		formatFlags(m.Flags),
+	}
+
+	for _, column := range csvColumns {
+		header = append(header, column.name)
+		row = append(row, column.value(report.Results))
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.Write(row)
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatCSVFloat formats a float the same way encoding/json would
+func formatCSVFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatCSVCounts flattens counters to "key=value;key=value" in key order
+func formatCSVCounts[T int | int64](counts map[string]T) string {
+	values := make(map[string]string, len(counts))
+	for name, count := range counts {
+		values[name] = strconv.FormatInt(int64(count), 10)
+	}
+	return formatFlags(values)
+}
+
+// formatCSVJSON encodes nested results as JSON, or returns "" if present is false
+func formatCSVJSON(present bool, v any) string {
+	if !present {
+		return ""
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// formatFlags renders flags, or any string map, as a single sorted "name=value;name=value" string
+func formatFlags(flags map[string]string) string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + flags[name]
+	}
+	return strings.Join(pairs, ";")
+}
+
+// flagValues captures the value of every flag, leaving out the account key
+func flagValues(cmd *cobra.Command) map[string]string {
+	flags := map[string]string{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "key" || f.Name == "help" {
+			return
+		}
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
+
+// gitSHA returns the commit the binary was built from, falling back to the working directory's HEAD for `go run`
+func gitSHA() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		revision, modified := "", false
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if revision != "" {
+			if modified {
+				revision += "-dirty"
+			}
+			return revision
+		}
+	}
+
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// cpuModel returns the host CPU model name from /proc/cpuinfo, or "" where that is unavailable
+func cpuModel() string {
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(name) == "model name" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package harness
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCSVReport(t *testing.T) {
+	report := &Report{
+		SchemaVersion: ReportSchemaVersion,
+		Metadata:      RunMetadata{Implementation: "Go", Operation: "pointRead", Flags: map[string]string{"workers": "4", "duration": "10s"}},
+		Results: &Results{
+			TotalOps:      1000,
+			ElapsedTime:   time.Second,
+			OpsPerSecond:  1000,
+			P99Ms:         1.5,
+			Errors:        3,
+			ErrorsByClass: map[string]int{"503": 1, "429/3200": 2},
+			Process:       &ProcessStats{Samples: 2},
+		},
+	}
+
+	var b strings.Builder
+	if err := writeCSVReport(&b, report); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing these columns breaks consumers of the CSV, so it requires a new ReportSchemaVersion
+	expected := []string{
+		"schemaVersion", "implementation", "operation", "timestamp", "gitSha", "goVersion", "gomaxprocs", "numCpu", "os", "arch", "cpuModel", "flags",
+		"totalOps", "elapsedTime", "opsPerSecond", "latencyMs", "p50Ms", "p90Ms", "p99Ms", "p999Ms", "maxMs", "allocsPerOp", "bytesPerOp",
+		"errors", "errorRate", "errorsByClass", "errorLatencyMs", "errorP99Ms", "operations", "steps", "process", "nativeStats",
+		"iterations", "targetRate", "lateOps", "droppedOps", "seed",
+	}
+	if ReportSchemaVersion != 1 || !slices.Equal(records[0], expected) {
+		t.Fatalf("CSV columns changed for schema version %d:\n%v", ReportSchemaVersion, records[0])
+	}
+	if len(records) != 2 || len(records[1]) != len(expected) {
+		t.Fatalf("expected a single row of %d values, got %v", len(expected), records[1:])
+	}
+
+	row := map[string]string{}
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+	checks := map[string]string{
+		"schemaVersion": "1",
+		"flags":         "duration=10s;workers=4",
+		"elapsedTime":   "1000000000",
+		"p99Ms":         "1.5",
+		"errorsByClass": "429/3200=2;503=1",
+		"process":       `{"samples":2,`,
+		"operations":    "",
+	}
+	for name, want := range checks {
+		if got := row[name]; !strings.HasPrefix(got, want) || (want == "" && got != "") {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package harness
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// processSampleInterval is how often the runner samples process CPU and memory usage
+const processSampleInterval = 500 * time.Millisecond
+
+// userHZ is the unit of the CPU times in /proc/<pid>/stat, which Linux fixes at 100 ticks per second
+const userHZ = 100
+
+// ProcessStats describes the benchmark process's resource usage while it was measuring. CPU time,
+// RSS and thread counts come from /proc/self, so they are only reported on Linux.
+type ProcessStats struct {
+	Samples int `json:"samples"`
+
+	// CPUPercent is CPU time as a percentage of elapsed time, so two busy cores are 200%.
+	// CPUPerCorePercent divides it by the number of CPUs, and PeakCPUPercent is the highest
+	// CPUPercent between two samples.
+	CPUUserSeconds    float64 `json:"cpuUserSeconds"`
+	CPUSystemSeconds  float64 `json:"cpuSystemSeconds"`
+	CPUPercent        float64 `json:"cpuPercent"`
+	CPUPerCorePercent float64 `json:"cpuPerCorePercent"`
+	PeakCPUPercent    float64 `json:"peakCpuPercent"`
+
+	AvgRSSBytes    uint64  `json:"avgRssBytes"`
+	PeakRSSBytes   uint64  `json:"peakRssBytes"`
+	AvgHeapBytes   uint64  `json:"avgHeapBytes"`
+	PeakHeapBytes  uint64  `json:"peakHeapBytes"`
+	AvgGoroutines  float64 `json:"avgGoroutines"`
+	PeakGoroutines int     `json:"peakGoroutines"`
+	AvgThreads     float64 `json:"avgThreads"`
+	PeakThreads    int     `json:"peakThreads"`
+}
+
+// processSample is a snapshot of the process. hasProc is false where /proc/self/stat is unavailable.
+type processSample struct {
+	time       time.Time
+	hasProc    bool
+	userSecs   float64
+	systemSecs float64
+	threads    int
+	rssBytes   uint64
+	heapBytes  uint64
+	goroutines int
+}
+
+// Go runtime metrics sampled alongside /proc/self/stat
+var processMetrics = []string{
+	"/memory/classes/heap/objects:bytes",
+	"/sched/goroutines:goroutines",
+}
+
+// readProcessSample takes a snapshot of the process, reusing metricSamples
+func readProcessSample(metricSamples []metrics.Sample) processSample {
+	s := processSample{time: time.Now()}
+
+	metrics.Read(metricSamples)
+	s.heapBytes = metricSamples[0].Value.Uint64()
+	s.goroutines = int(metricSamples[1].Value.Uint64())
+
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return s
+	}
+	// The command name in field 2 may contain spaces and parentheses, so split the fields after it
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return s
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return s
+	}
+	// fields[0] is field 3 (state): utime is 14, stime 15, num_threads 20 and rss 24
+	utime, err1 := strconv.ParseUint(fields[11], 10, 64)
+	stime, err2 := strconv.ParseUint(fields[12], 10, 64)
+	threads, err3 := strconv.Atoi(fields[17])
+	rss, err4 := strconv.ParseUint(fields[21], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return s
+	}
+	s.hasProc = true
+	s.userSecs = float64(utime) / userHZ
+	s.systemSecs = float64(stime) / userHZ
+	s.threads = threads
+	s.rssBytes = rss * uint64(os.Getpagesize())
+	return s
+}
+
+// processSampler samples the process periodically while it is started. It accumulates across
+// every start and stop, so pauses between measured phases are excluded.
+type processSampler struct {
+	metricSamples []metrics.Sample
+
+	stopCh chan struct{}
+	done   chan struct{}
+	last   processSample
+
+	// Totals over every sampled window. procElapsed only counts windows with CPU times.
+	procElapsed  time.Duration
+	userSecs     float64
+	systemSecs   float64
+	peakCPU      float64
+	samples      int
+	procSamples  int
+	rssSum       float64
+	heapSum      float64
+	goroutineSum float64
+	threadSum    float64
+	peakRSS      uint64
+	peakHeap     uint64
+	peakRoutines int
+	peakThreads  int
+}
+
+func newProcessSampler() *processSampler {
+	s := &processSampler{}
+	for _, name := range processMetrics {
+		s.metricSamples = append(s.metricSamples, metrics.Sample{Name: name})
+	}
+	return s
+}
+
+// start begins sampling every processSampleInterval until stop is called
+func (s *processSampler) start() {
+	s.last = readProcessSample(s.metricSamples)
+	s.stopCh = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(processSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.record(readProcessSample(s.metricSamples))
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// stop takes a final sample and stops sampling. It does nothing if the sampler isn't running.
+func (s *processSampler) stop() {
+	if s.stopCh == nil {
+		return
+	}
+	close(s.stopCh)
+	<-s.done
+	s.stopCh = nil
+	s.record(readProcessSample(s.metricSamples))
+}
+
+// record adds the window since the previous sample
+func (s *processSampler) record(sample processSample) {
+	window := sample.time.Sub(s.last.time)
+
+	s.samples++
+	s.heapSum += float64(sample.heapBytes)
+	s.goroutineSum += float64(sample.goroutines)
+	s.peakHeap = max(s.peakHeap, sample.heapBytes)
+	s.peakRoutines = max(s.peakRoutines, sample.goroutines)
+
+	if sample.hasProc && s.last.hasProc {
+		cpu := sample.userSecs - s.last.userSecs + sample.systemSecs - s.last.systemSecs
+		s.userSecs += sample.userSecs - s.last.userSecs
+		s.systemSecs += sample.systemSecs - s.last.systemSecs
+		s.procElapsed += window
+		// Short windows, like the last one before stop, are too coarse for the clock tick resolution
+		if window >= processSampleInterval/2 {
+			s.peakCPU = max(s.peakCPU, 100*cpu/window.Seconds())
+		}
+
+		s.procSamples++
+		s.rssSum += float64(sample.rssBytes)
+		s.threadSum += float64(sample.threads)
+		s.peakRSS = max(s.peakRSS, sample.rssBytes)
+		s.peakThreads = max(s.peakThreads, sample.threads)
+	}
+	s.last = sample
+}
+
+// results summarizes every sample taken so far
+func (s *processSampler) results() *ProcessStats {
+	if s.samples == 0 {
+		return nil
+	}
+	stats := &ProcessStats{
+		Samples:        s.samples,
+		AvgHeapBytes:   uint64(s.heapSum / float64(s.samples)),
+		PeakHeapBytes:  s.peakHeap,
+		AvgGoroutines:  s.goroutineSum / float64(s.samples),
+		PeakGoroutines: s.peakRoutines,
+	}
+	if s.procSamples > 0 {
+		stats.CPUUserSeconds = s.userSecs
+		stats.CPUSystemSeconds = s.systemSecs
+		stats.CPUPercent = 100 * (s.userSecs + s.systemSecs) / s.procElapsed.Seconds()
+		stats.CPUPerCorePercent = stats.CPUPercent / float64(runtime.NumCPU())
+		stats.PeakCPUPercent = max(s.peakCPU, stats.CPUPercent)
+		stats.AvgRSSBytes = uint64(s.rssSum / float64(s.procSamples))
+		stats.PeakRSSBytes = s.peakRSS
+		stats.AvgThreads = s.threadSum / float64(s.procSamples)
+		stats.PeakThreads = s.peakThreads
+	}
+	return stats
+}
+
+// Print writes the process statistics to stdout
+func (p *ProcessStats) Print() {
+	if p.PeakThreads > 0 {
+		fmt.Printf("CPU: %.1f%% (%.1f%% per core, peak %.1f%%), %.2fs user, %.2fs system\n",
+			p.CPUPercent, p.CPUPerCorePercent, p.PeakCPUPercent, p.CPUUserSeconds, p.CPUSystemSeconds)
+		fmt.Printf("RSS (avg/peak): %.1f / %.1f MB\n", megabytes(p.AvgRSSBytes), megabytes(p.PeakRSSBytes))
+		fmt.Printf("OS threads (avg/peak): %.1f / %d\n", p.AvgThreads, p.PeakThreads)
+	}
+	fmt.Printf("Go heap (avg/peak): %.1f / %.1f MB\n", megabytes(p.AvgHeapBytes), megabytes(p.PeakHeapBytes))
+	fmt.Printf("Goroutines (avg/peak): %.1f / %d\n", p.AvgGoroutines, p.PeakGoroutines)
+}
+
+func megabytes(bytes uint64) float64 {
+	return float64(bytes) / (1 << 20)
+}
@@ -0,0 +1,36 @@
+package harness
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestProcessSampler(t *testing.T) {
+	sampler := newProcessSampler()
+
+	// Keep one core busy for two sampling windows, then pause, which shouldn't count
+	sampler.start()
+	for deadline := time.Now().Add(2 * processSampleInterval); time.Now().Before(deadline); {
+	}
+	sampler.stop()
+	time.Sleep(processSampleInterval)
+
+	stats := sampler.results()
+	if stats == nil || stats.Samples < 2 {
+		t.Fatalf("expected at least 2 samples, got %+v", stats)
+	}
+	if stats.PeakHeapBytes == 0 || stats.PeakGoroutines == 0 {
+		t.Errorf("expected Go heap and goroutine counts, got %+v", stats)
+	}
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	if stats.CPUPercent < 50 || stats.CPUPercent > 100*float64(runtime.NumCPU())+10 {
+		t.Errorf("expected a busy core to use most of a CPU, got %.1f%%", stats.CPUPercent)
+	}
+	if stats.PeakRSSBytes == 0 || stats.PeakThreads == 0 {
+		t.Errorf("expected RSS and thread counts, got %+v", stats)
+	}
+}
@@ -0,0 +1,181 @@
+package harness
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Results summarizes a benchmark run
+type Results struct {
+	TotalOps     int           `json:"totalOps"`
+	ElapsedTime  time.Duration `json:"elapsedTime"`
+	OpsPerSecond float64       `json:"opsPerSecond"`
+	LatencyMs    float64       `json:"latencyMs"`
+	P50Ms        float64       `json:"p50Ms"`
+	P90Ms        float64       `json:"p90Ms"`
+	P99Ms        float64       `json:"p99Ms"`
+	P999Ms       float64       `json:"p999Ms"`
+	MaxMs        float64       `json:"maxMs"`
+	AllocsPerOp  float64       `json:"allocsPerOp"`
+	BytesPerOp   float64       `json:"bytesPerOp"`
+
+	// Failed operations are excluded from TotalOps and the latency statistics above. ErrorRate is
+	// the fraction of all attempted operations that failed.
+	Errors         int            `json:"errors"`
+	ErrorRate      float64        `json:"errorRate"`
+	ErrorsByClass  map[string]int `json:"errorsByClass"`
+	ErrorLatencyMs float64        `json:"errorLatencyMs"`
+	ErrorP99Ms     float64        `json:"errorP99Ms"`
+
+	// Operations breaks the results down by kind of operation for mixed workloads
+	Operations map[string]*Summary `json:"operations,omitempty"`
+
+	// Steps breaks the results down by ramp-up step
+	Steps []*StepResults `json:"steps,omitempty"`
+
+	// Process describes the CPU and memory the benchmark process used while measuring
+	Process *ProcessStats `json:"process,omitempty"`
+
+	// NativeStats holds the counters of a NativeStatsReporter workload at the end of the run
+	NativeStats map[string]int64 `json:"nativeStats,omitempty"`
+
+	// Iterations summarizes each iteration of a multi-iteration run, and how much they vary
+	Iterations *IterationResults `json:"iterations,omitempty"`
+
+	// Open-loop runs only: the target rate, operations that started more than lateThreshold after
+	// they were scheduled, and operations dropped because the scheduling backlog was full
+	TargetRate float64 `json:"targetRate"`
+	LateOps    int     `json:"lateOps"`
+	DroppedOps int     `json:"droppedOps"`
+
+	// Seed is the random seed the run used, to reproduce its key and operation choices
+	Seed int64 `json:"seed"`
+}
+
+// Summary summarizes the successful operations of one kind in a mixed workload, or of one ramp-up step
+type Summary struct {
+	TotalOps     int     `json:"totalOps"`
+	OpsPerSecond float64 `json:"opsPerSecond"`
+	LatencyMs    float64 `json:"latencyMs"`
+	P50Ms        float64 `json:"p50Ms"`
+	P90Ms        float64 `json:"p90Ms"`
+	P99Ms        float64 `json:"p99Ms"`
+	P999Ms       float64 `json:"p999Ms"`
+	MaxMs        float64 `json:"maxMs"`
+	Errors       int     `json:"errors"`
+}
+
+// StepResults summarizes one step of a ramp-up run
+type StepResults struct {
+	Workers     int           `json:"workers"`
+	ElapsedTime time.Duration `json:"elapsedTime"`
+	Summary
+}
+
+func newSummary(latencies *Histogram, errors int, elapsed time.Duration) *Summary {
+	return &Summary{
+		TotalOps:     int(latencies.Count()),
+		OpsPerSecond: float64(latencies.Count()) / elapsed.Seconds(),
+		LatencyMs:    durationMs(latencies.Mean()),
+		P50Ms:        durationMs(latencies.Percentile(50)),
+		P90Ms:        durationMs(latencies.Percentile(90)),
+		P99Ms:        durationMs(latencies.Percentile(99)),
+		P999Ms:       durationMs(latencies.Percentile(99.9)),
+		MaxMs:        durationMs(latencies.Max()),
+		Errors:       errors,
+	}
+}
+
+// Print writes a human-readable summary of the results to stdout
+func (r *Results) Print() {
+	fmt.Printf("\n=== Benchmark Results ===\n")
+	fmt.Printf("Total ops: %d\n", r.TotalOps)
+	fmt.Printf("Total elapsed time: %v\n", r.ElapsedTime.Round(time.Millisecond))
+	fmt.Printf("Ops/sec: %.2f\n", r.OpsPerSecond)
+	fmt.Printf("Latency (mean): %.2f ms\n", r.LatencyMs)
+	fmt.Printf("Latency (p50/p90/p99/p99.9/max): %.2f / %.2f / %.2f / %.2f / %.2f ms\n",
+		r.P50Ms, r.P90Ms, r.P99Ms, r.P999Ms, r.MaxMs)
+	if r.Errors > 0 {
+		fmt.Printf("Errors: %d (%.2f%% of operations): %s\n", r.Errors, r.ErrorRate*100, formatCounts(r.ErrorsByClass))
+		fmt.Printf("Error latency (mean/p99): %.2f / %.2f ms\n", r.ErrorLatencyMs, r.ErrorP99Ms)
+	}
+	if r.TargetRate > 0 {
+		fmt.Printf("Target rate: %.2f ops/sec (%d late, %d dropped)\n", r.TargetRate, r.LateOps, r.DroppedOps)
+	}
+	fmt.Printf("Allocations: %.1f allocs/op, %.0f B/op\n", r.AllocsPerOp, r.BytesPerOp)
+	if r.Process != nil {
+		r.Process.Print()
+	}
+	if len(r.NativeStats) > 0 {
+		fmt.Printf("Native resources: %s\n", formatNativeStats(r.NativeStats))
+	}
+	if len(r.Operations) > 0 {
+		fmt.Printf("\nBy operation (ops/sec, mean/p50/p99/max ms, errors):\n")
+		for _, name := range r.OperationNames() {
+			op := r.Operations[name]
+			fmt.Printf("  %-8s %10.2f  %.2f / %.2f / %.2f / %.2f  %d\n",
+				name, op.OpsPerSecond, op.LatencyMs, op.P50Ms, op.P99Ms, op.MaxMs, op.Errors)
+		}
+	}
+	if len(r.Steps) > 0 {
+		fmt.Printf("\nBy ramp-up step (ops/sec, mean/p50/p99/max ms, errors):\n")
+		for _, step := range r.Steps {
+			fmt.Printf("  %3d workers %10.2f  %.2f / %.2f / %.2f / %.2f  %d\n",
+				step.Workers, step.OpsPerSecond, step.LatencyMs, step.P50Ms, step.P99Ms, step.MaxMs, step.Errors)
+		}
+	}
+	if it := r.Iterations; it != nil {
+		fmt.Printf("\nBy iteration (ops/sec, mean/p50/p99/max ms, errors):\n")
+		for _, run := range it.Runs {
+			fmt.Printf("  %3d %10.2f  %.2f / %.2f / %.2f / %.2f  %d\n",
+				run.Iteration, run.OpsPerSecond, run.LatencyMs, run.P50Ms, run.P99Ms, run.MaxMs, run.Errors)
+		}
+		fmt.Printf("\nAcross %d iterations (mean ± 95%% CI, stddev):\n", it.Count)
+		for _, metric := range []struct {
+			name string
+			stat Statistic
+		}{
+			{"Ops/sec", it.OpsPerSecond},
+			{"Latency (mean) ms", it.LatencyMs},
+			{"p50 ms", it.P50Ms},
+			{"p90 ms", it.P90Ms},
+			{"p99 ms", it.P99Ms},
+			{"p99.9 ms", it.P999Ms},
+		} {
+			fmt.Printf("  %-18s %s (stddev %.2f)\n", metric.name, metric.stat, metric.stat.StdDev)
+		}
+	}
+	fmt.Printf("========================\n")
+}
+
+// formatNativeStats renders native counters as "name=n, name=n", sorted by name
+func formatNativeStats(counters map[string]int64) string {
+	names := make([]string, 0, len(counters))
+	for name := range counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%d", name, counters[name])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// OperationNames returns the names of the operations in a mixed workload's results, sorted
+func (r *Results) OperationNames() []string {
+	names := make([]string, 0, len(r.Operations))
+	for name := range r.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,490 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTooManyErrors is returned by Run when a run is aborted because it exceeded Config.MaxErrors
+var ErrTooManyErrors = errors.New("too many errors")
+
+// Run executes workload with cfg.Workers concurrent workers for cfg.Duration and returns the
+// combined statistics. Each worker repeatedly picks an item key and executes its Operation.
+//
+// By default the run is closed-loop: each worker starts its next operation as soon as the previous
+// one completes. If cfg.Rate is set the run is open-loop: operations are scheduled at that rate and
+// handed to whichever worker is free, and latency is measured from the scheduled start time so
+// queueing delay caused by slow operations is not hidden (coordinated omission).
+//
+// If cfg.Warmup is set, the workers first run for that long without recording statistics, so
+// connection setup and client initialization don't skew the results. If cfg.RampSteps is set, the
+// measured run is a series of steps of cfg.Duration each, with the given number of workers, and
+// the results include statistics for every step as well as for the run as a whole.
+//
+// If cfg.Iterations is more than one, the measured run is repeated that many times, pausing for
+// cfg.Cooldown in between, after a single warmup. The results combine every iteration, and also
+// report the mean, standard deviation and confidence interval of each iteration's throughput and
+// latency percentiles.
+//
+// While measuring, the runner samples the process's CPU time, RSS, Go heap, goroutines and OS
+// threads, and reports their averages and peaks.
+//
+// Failed operations are counted by error class (see ErrorClassifier) and reported separately from
+// successful ones. If more than cfg.MaxErrors operations fail the run is aborted with ErrTooManyErrors.
+//
+// Every cfg.ReportInterval the runner prints the throughput, latency, errors and Go heap usage of
+// the interval just ended, and if cfg.ReportFile is set also writes them as a line of JSON, so
+// degradation over the course of a run is visible.
+func Run(ctx context.Context, cfg *Config, workload Workload) (*Results, error) {
+	var opMix *mix
+	if mixed, ok := workload.(MixedWorkload); ok {
+		var err error
+		if opMix, err = newMix(mixed.Operations()); err != nil {
+			return nil, err
+		}
+	}
+
+	classify := defaultErrorClass
+	if classifier, ok := workload.(ErrorClassifier); ok {
+		classify = classifier.ClassifyError
+	}
+
+	// Create a context that will be canceled with a cause when the run is aborted
+	abortCtx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+
+	state := &runState{cfg: cfg, mix: opMix, classify: classify, abort: abort}
+	if reporter, ok := workload.(NativeStatsReporter); ok {
+		state.nativeStats = reporter.NativeStats
+	}
+
+	if cfg.ReportFile != "" {
+		f, err := os.Create(cfg.ReportFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer f.Close()
+		state.intervalOut = newIntervalWriter(f)
+	}
+
+	steps := cfg.RampSteps
+	if len(steps) == 0 {
+		steps = []int{cfg.Workers}
+	}
+
+	workers := make([]*worker, cfg.MaxWorkers())
+	for i := range workers {
+		op, err := workload.NewWorker(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create worker %d: %w", i, err)
+		}
+		w := &worker{id: i, op: op, state: state, stats: state.newStats()}
+		if cfg.ReportInterval > 0 {
+			w.interval = newIntervalStats()
+		}
+		if opMix != nil {
+			var ok bool
+			if w.mixed, ok = op.(MixedOperation); !ok {
+				return nil, fmt.Errorf("workload %s is mixed but its operations don't implement MixedOperation", workload.Name())
+			}
+		}
+
+		// Give each worker a local random source to avoid contention, seeded so runs with the
+		// same seed replay the same keys and operations
+		w.localRand = rand.New(rand.NewSource(cfg.Seed + int64(i) + 1))
+		w.keys = cfg.Distribution.newKeyChooser(cfg.ItemCount, i, len(workers), w.localRand)
+		workers[i] = w
+	}
+
+	if cfg.Warmup > 0 {
+		fmt.Printf("Warming up for %v with %d workers\n", cfg.Warmup, steps[0])
+		if _, err := state.runPhase(abortCtx, PhaseWarmup, workers[:steps[0]], cfg.Warmup); err != nil {
+			return nil, fmt.Errorf("warmup failed: %w", err)
+		}
+		state.reset()
+	}
+
+	var startMem runtime.MemStats
+	runtime.ReadMemStats(&startMem)
+
+	// Sample CPU and memory usage while measuring, but not during warmup or cooldowns
+	sampler := newProcessSampler()
+	defer sampler.stop()
+
+	iterations := max(cfg.Iterations, 1)
+	total := state.newStats()
+	var actualElapsed time.Duration
+	var stepResults []*StepResults
+	var runs []*IterationRun
+	for iteration := 1; iteration <= iterations && ctx.Err() == nil; iteration++ {
+		if iteration > 1 && cfg.Cooldown > 0 {
+			fmt.Printf("Cooling down for %v\n", cfg.Cooldown)
+			select {
+			case <-time.After(cfg.Cooldown):
+			case <-ctx.Done():
+			}
+			// Don't start another iteration if the run was cancelled while cooling down
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		startTime := time.Now()
+		if iterations > 1 {
+			fmt.Printf("Iteration %d/%d started at %v with %d workers\n", iteration, iterations, startTime.Format("15:04:05.000"), steps[0])
+		} else {
+			fmt.Printf("Benchmark started at %v with %d workers\n", startTime.Format("15:04:05.000"), steps[0])
+		}
+
+		measured := state.newStats()
+		sampler.start()
+		for i, n := range steps {
+			if len(cfg.RampSteps) > 0 && i > 0 {
+				fmt.Printf("Ramping up to %d workers\n", n)
+			}
+
+			phaseStart := time.Now()
+			phase, err := state.runPhase(abortCtx, PhaseMeasure, workers[:n], cfg.Duration)
+			if err != nil {
+				return nil, err
+			}
+			measured.merge(phase)
+
+			if len(cfg.RampSteps) > 0 {
+				elapsed := time.Since(phaseStart)
+				step := &StepResults{Workers: n, ElapsedTime: elapsed, Summary: *phase.summary(elapsed)}
+				fmt.Printf("Step %d/%d: %d workers, %.1f ops/sec, p50 %.2f ms, p99 %.2f ms, %d errors\n",
+					i+1, len(steps), n, step.OpsPerSecond, step.P50Ms, step.P99Ms, step.Errors)
+				stepResults = append(stepResults, step)
+			}
+
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		elapsed := time.Since(startTime)
+		sampler.stop()
+		actualElapsed += elapsed
+		total.merge(measured)
+
+		if iterations > 1 {
+			run := &IterationRun{Iteration: iteration, ElapsedTime: elapsed, Summary: *measured.summary(elapsed)}
+			fmt.Printf("Iteration %d/%d: %.1f ops/sec, p50 %.2f ms, p99 %.2f ms, %d errors\n",
+				iteration, iterations, run.OpsPerSecond, run.P50Ms, run.P99Ms, run.Errors)
+			runs = append(runs, run)
+		}
+	}
+
+	var endMem runtime.MemStats
+	runtime.ReadMemStats(&endMem)
+
+	finalOps := total.latencies.Count()
+	finalErrors := total.errors()
+	if finalOps == 0 {
+		if finalErrors > 0 {
+			return nil, fmt.Errorf("no operations completed, %d failed (%s)", finalErrors, formatCounts(total.errorsByClass))
+		}
+		return nil, fmt.Errorf("no operations completed")
+	}
+
+	var byOperation map[string]*Summary
+	if opMix != nil {
+		byOperation = make(map[string]*Summary, len(opMix.names))
+		for kind, name := range opMix.names {
+			byOperation[name] = total.kinds[kind].summary(actualElapsed)
+		}
+	}
+
+	var iterationResults *IterationResults
+	if len(runs) > 0 {
+		iterationResults = newIterationResults(runs, cfg.Cooldown)
+	}
+
+	attempted := float64(finalOps + finalErrors)
+	results := &Results{
+		TotalOps:       int(finalOps),
+		ElapsedTime:    actualElapsed,
+		OpsPerSecond:   float64(finalOps) / actualElapsed.Seconds(),
+		LatencyMs:      durationMs(total.latencies.Mean()),
+		P50Ms:          durationMs(total.latencies.Percentile(50)),
+		P90Ms:          durationMs(total.latencies.Percentile(90)),
+		P99Ms:          durationMs(total.latencies.Percentile(99)),
+		P999Ms:         durationMs(total.latencies.Percentile(99.9)),
+		MaxMs:          durationMs(total.latencies.Max()),
+		AllocsPerOp:    float64(endMem.Mallocs-startMem.Mallocs) / attempted,
+		BytesPerOp:     float64(endMem.TotalAlloc-startMem.TotalAlloc) / attempted,
+		Errors:         int(finalErrors),
+		ErrorRate:      float64(finalErrors) / attempted,
+		ErrorsByClass:  total.errorsByClass,
+		ErrorLatencyMs: durationMs(total.errorLatencies.Mean()),
+		ErrorP99Ms:     durationMs(total.errorLatencies.Percentile(99)),
+		Operations:     byOperation,
+		Steps:          stepResults,
+		Iterations:     iterationResults,
+		Process:        sampler.results(),
+		NativeStats:    state.snapshotNativeStats(),
+		TargetRate:     cfg.Rate,
+		LateOps:        int(atomic.LoadInt64(&state.lateOps)),
+		DroppedOps:     int(atomic.LoadInt64(&state.droppedOps)),
+		Seed:           cfg.Seed,
+	}
+
+	return results, nil
+}
+
+// runState is shared by all the workers in a run
+type runState struct {
+	cfg      *Config
+	mix      *mix
+	classify func(error) string
+
+	// nativeStats is set for workloads that implement NativeStatsReporter
+	nativeStats func() map[string]int64
+	abort       context.CancelCauseFunc
+
+	// intervalOut receives interval reports if a report file was given
+	intervalOut *intervalWriter
+
+	// Counters for the error threshold and open-loop scheduling, updated atomically
+	totalErrors int64
+	lateOps     int64
+	droppedOps  int64
+}
+
+// snapshotNativeStats returns the workload's native counters, or nil if it doesn't report any
+func (s *runState) snapshotNativeStats() map[string]int64 {
+	if s.nativeStats == nil {
+		return nil
+	}
+	return s.nativeStats()
+}
+
+// reset clears the counters after the warmup phase
+func (s *runState) reset() {
+	atomic.StoreInt64(&s.totalErrors, 0)
+	atomic.StoreInt64(&s.lateOps, 0)
+	atomic.StoreInt64(&s.droppedOps, 0)
+}
+
+// runPhase runs workers for duration and returns their combined statistics. Each worker's
+// statistics are reset, so phases are measured independently.
+func (s *runState) runPhase(ctx context.Context, phaseName string, workers []*worker, duration time.Duration) (*stats, error) {
+	phaseCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	startTime := time.Now()
+
+	// In open-loop mode workers take their start times from the scheduler
+	var arrivals chan time.Time
+	if s.cfg.Rate > 0 {
+		arrivals = make(chan time.Time, scheduleBacklog)
+		go schedule(phaseCtx, rand.New(rand.NewSource(s.cfg.Seed)), s.cfg.Rate, s.cfg.Arrival, arrivals, &s.droppedOps)
+	}
+
+	// WaitGroup to wait for all workers to complete
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		w.arrivals = arrivals
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			w.run(phaseCtx)
+		}(w)
+	}
+
+	// Report every interval, then once more for the partial interval at the end of the phase
+	reporter := newIntervalReporter(s, phaseName, workers, startTime, duration, arrivals != nil)
+	reporterDone := make(chan struct{})
+	if s.cfg.ReportInterval > 0 {
+		go func() {
+			defer close(reporterDone)
+			reporter.run(s.cfg.ReportInterval, phaseCtx.Done())
+		}()
+	} else {
+		close(reporterDone)
+	}
+
+	// Wait for the phase duration or context cancellation, then for all workers to finish
+	<-phaseCtx.Done()
+	wg.Wait()
+	<-reporterDone
+	if s.cfg.ReportInterval > 0 {
+		reporter.flush(time.Now())
+	}
+
+	phase := s.newStats()
+	for _, w := range workers {
+		phase.merge(w.stats)
+		w.stats = s.newStats()
+	}
+
+	if cause := context.Cause(ctx); errors.Is(cause, ErrTooManyErrors) {
+		return nil, fmt.Errorf("%w: run aborted after %d errors (%s)", ErrTooManyErrors, atomic.LoadInt64(&s.totalErrors), formatCounts(phase.errorsByClass))
+	}
+	return phase, nil
+}
+
+// stats accumulates measurements. Each worker records into its own, and they are merged after each phase.
+type stats struct {
+	latencies      *Histogram
+	errorLatencies *Histogram
+	errorsByClass  map[string]int
+
+	// kinds has one entry per operation kind of a mixed workload
+	kinds []*kindStats
+}
+
+// kindStats records the statistics for one kind of operation in a mixed workload
+type kindStats struct {
+	latencies *Histogram
+	errors    int
+}
+
+func (s *runState) newStats() *stats {
+	st := &stats{
+		latencies:      NewHistogram(),
+		errorLatencies: NewHistogram(),
+		errorsByClass:  map[string]int{},
+	}
+	if s.mix != nil {
+		for range s.mix.names {
+			st.kinds = append(st.kinds, &kindStats{latencies: NewHistogram()})
+		}
+	}
+	return st
+}
+
+func (s *stats) merge(other *stats) {
+	s.latencies.Merge(other.latencies)
+	s.errorLatencies.Merge(other.errorLatencies)
+	for class, n := range other.errorsByClass {
+		s.errorsByClass[class] += n
+	}
+	for i, k := range other.kinds {
+		s.kinds[i].latencies.Merge(k.latencies)
+		s.kinds[i].errors += k.errors
+	}
+}
+
+func (s *stats) errors() int64 {
+	return s.errorLatencies.Count()
+}
+
+func (s *stats) summary(elapsed time.Duration) *Summary {
+	return newSummary(s.latencies, int(s.errors()), elapsed)
+}
+
+func (k *kindStats) summary(elapsed time.Duration) *Summary {
+	return newSummary(k.latencies, k.errors, elapsed)
+}
+
+// worker executes operations for one Operation instance
+type worker struct {
+	id        int
+	op        Operation
+	localRand *rand.Rand
+	keys      keyChooser
+
+	// arrivals carries scheduled start times in open-loop mode, and is nil in closed-loop mode
+	arrivals <-chan time.Time
+
+	// mixed is set for MixedWorkloads
+	mixed MixedOperation
+
+	state *runState
+	stats *stats
+
+	// interval is shared with the reporter, unlike stats. It is nil when interval reports are off.
+	interval *intervalStats
+}
+
+func (w *worker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Measure latency from the intended start time, which in closed-loop mode is now
+		opStart := time.Now()
+		if w.arrivals != nil {
+			intended, ok := <-w.arrivals
+			if !ok {
+				return
+			}
+			if opStart.Sub(intended) > lateThreshold {
+				atomic.AddInt64(&w.state.lateOps, 1)
+			}
+			opStart = intended
+		}
+
+		key := w.keys.next()
+
+		var err error
+		kind := -1
+		if w.mixed != nil {
+			kind, err = w.executeMixed(ctx, key)
+		} else {
+			err = w.op.Execute(ctx, key)
+		}
+
+		opLatency := time.Since(opStart)
+
+		if err != nil {
+			if ctx.Err() != nil {
+				// The phase ended while this operation was in flight
+				return
+			}
+			if kind >= 0 {
+				w.stats.kinds[kind].errors++
+			}
+			w.recordError(err, key, opLatency)
+			continue
+		}
+
+		// Record latency in this worker's histograms
+		w.stats.latencies.Record(opLatency)
+		if w.interval != nil {
+			w.interval.recordSuccess(opLatency)
+		}
+		if kind >= 0 {
+			w.stats.kinds[kind].latencies.Record(opLatency)
+		}
+	}
+}
+
+// executeMixed picks an operation kind and executes it, picking again if the operation is skipped
+func (w *worker) executeMixed(ctx context.Context, key int) (int, error) {
+	for range maxSkips {
+		kind := w.state.mix.pick(w.localRand)
+		err := w.mixed.ExecuteOperation(ctx, kind, key)
+		if !errors.Is(err, ErrSkipped) {
+			return kind, err
+		}
+	}
+	return -1, fmt.Errorf("%w %d times in a row", ErrSkipped, maxSkips)
+}
+
+// recordError counts a failed operation, logs a sample of failures, and aborts the run once
+// the error threshold is exceeded
+func (w *worker) recordError(err error, key int, latency time.Duration) {
+	class := w.state.classify(err)
+	w.stats.errorsByClass[class]++
+	w.stats.errorLatencies.Record(latency)
+	if w.interval != nil {
+		w.interval.recordError()
+	}
+
+	cfg := w.state.cfg
+	if cfg.ErrorSample > 0 && w.localRand.Float64() < cfg.ErrorSample {
+		fmt.Printf("Worker %d: Error executing operation on item %d [%s]: %v\n", w.id, key, class, err)
+	}
+
+	total := atomic.AddInt64(&w.state.totalErrors, 1)
+	if cfg.MaxErrors > 0 && total > int64(cfg.MaxErrors) {
+		w.state.abort(fmt.Errorf("%w: more than %d operations failed", ErrTooManyErrors, cfg.MaxErrors))
+	}
+}
@@ -0,0 +1,297 @@
+package harness
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sleepWorkload sleeps for a fixed time on every operation and counts the keys it was given
+type sleepWorkload struct {
+	delay     time.Duration
+	itemCount int
+	badKeys   atomic.Int64
+	executed  atomic.Int64
+}
+
+func (w *sleepWorkload) Name() string {
+	return "sleep"
+}
+
+func (w *sleepWorkload) NewWorker(workerID int) (Operation, error) {
+	return w, nil
+}
+
+func (w *sleepWorkload) Execute(ctx context.Context, key int) error {
+	if key < 0 || key >= w.itemCount {
+		w.badKeys.Add(1)
+	}
+	w.executed.Add(1)
+	select {
+	case <-time.After(w.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestRun(t *testing.T) {
+	workload := &sleepWorkload{delay: time.Millisecond, itemCount: 10}
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 4, Duration: 200 * time.Millisecond}
+
+	results, err := Run(context.Background(), cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results.TotalOps == 0 {
+		t.Fatal("expected operations to complete")
+	}
+	if n := workload.badKeys.Load(); n != 0 {
+		t.Errorf("%d operations received keys outside [0, %d)", n, cfg.ItemCount)
+	}
+	if results.P50Ms < 1 || results.P50Ms > results.MaxMs {
+		t.Errorf("expected p50 of at least 1ms and at most max %vms, got %vms", results.MaxMs, results.P50Ms)
+	}
+}
+
+func TestRunWarmupAndRampUp(t *testing.T) {
+	workload := &sleepWorkload{delay: time.Millisecond, itemCount: 10}
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Duration: 150 * time.Millisecond, Warmup: 100 * time.Millisecond, RampSteps: []int{1, 4}}
+
+	results, err := Run(context.Background(), cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results.Steps) != 2 || results.Steps[0].Workers != 1 || results.Steps[1].Workers != 4 {
+		t.Fatalf("expected steps with 1 and 4 workers, got %+v", results.Steps)
+	}
+	if results.Steps[0].TotalOps+results.Steps[1].TotalOps != results.TotalOps {
+		t.Errorf("step totals don't add up to %d", results.TotalOps)
+	}
+	if results.Steps[1].OpsPerSecond < 2*results.Steps[0].OpsPerSecond {
+		t.Errorf("expected 4 workers to be much faster than 1, got %.0f vs %.0f ops/sec", results.Steps[1].OpsPerSecond, results.Steps[0].OpsPerSecond)
+	}
+	if executed := workload.executed.Load(); executed < int64(results.TotalOps)+20 {
+		t.Errorf("expected warmup operations to be excluded, %d executed but %d reported", executed, results.TotalOps)
+	}
+}
+
+func TestRunOpenLoop(t *testing.T) {
+	// A single worker taking 20ms per operation can't keep up with 100 ops/sec, so operations queue
+	// and the latency measured from their scheduled start must grow well beyond the service time
+	workload := &sleepWorkload{delay: 20 * time.Millisecond, itemCount: 10}
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 1, Duration: 500 * time.Millisecond, Rate: 100, Arrival: ArrivalFixed}
+
+	results, err := Run(context.Background(), cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results.TotalOps > 30 {
+		t.Errorf("expected at most ~25 operations from one 20ms worker, got %d", results.TotalOps)
+	}
+	if results.LateOps == 0 {
+		t.Error("expected late operations when the rate exceeds capacity")
+	}
+	if results.MaxMs < 100 {
+		t.Errorf("expected queueing delay to be included in latency, got max %vms", results.MaxMs)
+	}
+}
+
+func TestRunWritesIntervalReports(t *testing.T) {
+	workload := &sleepWorkload{delay: time.Millisecond, itemCount: 10}
+	reportFile := filepath.Join(t.TempDir(), "intervals.jsonl")
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 2, Duration: 350 * time.Millisecond, Warmup: 100 * time.Millisecond,
+		ReportInterval: 100 * time.Millisecond, ReportFile: reportFile}
+
+	results, err := Run(context.Background(), cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(reportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var reports []IntervalReport
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var report IntervalReport
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			t.Fatalf("invalid interval report %q: %v", scanner.Text(), err)
+		}
+		reports = append(reports, report)
+	}
+
+	measured := 0
+	for _, report := range reports {
+		if report.Phase == PhaseMeasure {
+			measured += report.Ops
+		}
+	}
+	if len(reports) < 4 || reports[0].Phase != PhaseWarmup || reports[len(reports)-1].Phase != PhaseMeasure {
+		t.Fatalf("expected warmup reports followed by at least 3 measured ones, got %+v", reports)
+	}
+	if measured != results.TotalOps {
+		t.Errorf("measured intervals add up to %d operations, expected %d", measured, results.TotalOps)
+	}
+}
+
+func TestIntervalReportWithNoElapsedTime(t *testing.T) {
+	var out bytes.Buffer
+	state := &runState{cfg: &Config{}, intervalOut: newIntervalWriter(&out)}
+	w := &worker{interval: newIntervalStats()}
+	w.interval.recordSuccess(time.Millisecond)
+
+	// A flush at the same instant as the previous report must still encode
+	start := time.Now()
+	reporter := newIntervalReporter(state, PhaseMeasure, []*worker{w}, start, time.Second, false)
+	reporter.flush(start)
+
+	var report IntervalReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid interval report %q: %v", out.String(), err)
+	}
+	if report.Ops != 1 || report.OpsPerSecond != 0 {
+		t.Errorf("expected 1 operation at 0 ops/sec, got %d at %v", report.Ops, report.OpsPerSecond)
+	}
+}
+
+func TestRunIterations(t *testing.T) {
+	workload := &sleepWorkload{delay: time.Millisecond, itemCount: 10}
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 2, Duration: 100 * time.Millisecond, Iterations: 3, Cooldown: 50 * time.Millisecond}
+
+	start := time.Now()
+	results, err := Run(context.Background(), cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := results.Iterations
+	if it == nil || it.Count != 3 || len(it.Runs) != 3 {
+		t.Fatalf("expected 3 iterations, got %+v", it)
+	}
+	sum := 0
+	for _, run := range it.Runs {
+		sum += run.TotalOps
+	}
+	if sum != results.TotalOps {
+		t.Errorf("iterations add up to %d operations, expected %d", sum, results.TotalOps)
+	}
+	if it.OpsPerSecond.Mean < it.OpsPerSecond.Min || it.OpsPerSecond.Mean > it.OpsPerSecond.Max || it.OpsPerSecond.CI95 <= 0 {
+		t.Errorf("unexpected throughput statistic %+v", it.OpsPerSecond)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected cooldowns between iterations, took %v", elapsed)
+	}
+}
+
+func TestRunIterationsCancelledDuringCooldown(t *testing.T) {
+	workload := &sleepWorkload{delay: time.Millisecond, itemCount: 10}
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 2, Duration: 100 * time.Millisecond, Iterations: 3, Cooldown: 10 * time.Second}
+
+	// Cancel during the first cooldown
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results, err := Run(ctx, cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if it := results.Iterations; it == nil || len(it.Runs) != 1 {
+		t.Fatalf("expected only the first iteration to run, got %+v", it)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the run to stop when cancelled, took %v", elapsed)
+	}
+}
+
+// nativeWorkload reports the number of operations it executed as a native counter
+type nativeWorkload struct {
+	*sleepWorkload
+}
+
+func (w nativeWorkload) NativeStats() map[string]int64 {
+	return map[string]int64{"executed": w.executed.Load()}
+}
+
+func TestRunReportsNativeStats(t *testing.T) {
+	workload := nativeWorkload{&sleepWorkload{delay: time.Millisecond, itemCount: 10}}
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 2, Duration: 100 * time.Millisecond}
+
+	results, err := Run(context.Background(), cfg, workload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.NativeStats["executed"] < int64(results.TotalOps) {
+		t.Errorf("expected native stats from the end of the run, got %v for %d operations", results.NativeStats, results.TotalOps)
+	}
+}
+
+// flakyWorkload fails operations on odd keys
+type flakyWorkload struct{}
+
+var errOddKey = errors.New("odd key")
+
+func (w flakyWorkload) Name() string                              { return "flaky" }
+func (w flakyWorkload) NewWorker(workerID int) (Operation, error) { return w, nil }
+func (w flakyWorkload) ClassifyError(err error) string            { return StatusClass(503, 0) }
+func (w flakyWorkload) Execute(ctx context.Context, key int) error {
+	time.Sleep(100 * time.Microsecond)
+	if key%2 == 1 {
+		return errOddKey
+	}
+	return nil
+}
+
+func TestRunCountsErrors(t *testing.T) {
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 2, Duration: 200 * time.Millisecond}
+
+	results, err := Run(context.Background(), cfg, flakyWorkload{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results.Errors == 0 || results.ErrorsByClass["503"] != results.Errors {
+		t.Errorf("expected all errors to be classified as 503, got %v of %d", results.ErrorsByClass, results.Errors)
+	}
+	if results.ErrorRate < 0.3 || results.ErrorRate > 0.7 {
+		t.Errorf("expected about half of the operations to fail, got error rate %v", results.ErrorRate)
+	}
+}
+
+func TestRunAbortsOnMaxErrors(t *testing.T) {
+	cfg := &Config{ItemCount: 10, PartitionCount: 2, Workers: 2, Duration: 10 * time.Second, MaxErrors: 5}
+
+	start := time.Now()
+	_, err := Run(context.Background(), cfg, flakyWorkload{})
+	if !errors.Is(err, ErrTooManyErrors) {
+		t.Fatalf("expected ErrTooManyErrors, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("expected the run to abort early, took %v", time.Since(start))
+	}
+}
+
+func TestNewItemKeys(t *testing.T) {
+	keys := NewItemKeys(5, 2)
+
+	if len(keys) != 5 {
+		t.Fatalf("expected 5 keys, got %d", len(keys))
+	}
+	if keys[3] != (ItemKey{ID: "item3", PartitionKey: "partition1"}) {
+		t.Errorf("unexpected key %+v", keys[3])
+	}
+}
@@ -0,0 +1,64 @@
+package harness
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Arrival processes for open-loop runs
+const (
+	ArrivalFixed   = "fixed"
+	ArrivalPoisson = "poisson"
+)
+
+// scheduleBacklog bounds how many scheduled operations can wait for a free worker. Arrivals
+// beyond it are dropped so an overloaded run can't queue work without limit.
+const scheduleBacklog = 10000
+
+// MaxRate is the highest --rate accepted. It is far beyond what one process can issue, and keeps
+// intervals long enough for the schedule to emit arrivals faster than they fall due.
+const MaxRate = 1e6
+
+// lateThreshold is how far behind its intended start time an operation can begin before it is
+// counted as late
+const lateThreshold = time.Millisecond
+
+// schedule emits intended operation start times at the given rate until ctx is done, then closes
+// arrivals. Intervals are constant for ArrivalFixed and exponentially distributed for ArrivalPoisson.
+// Start times are emitted once they are due; if the backlog is full the arrival is dropped.
+func schedule(ctx context.Context, localRand *rand.Rand, rate float64, arrival string, arrivals chan<- time.Time, dropped *int64) {
+	defer close(arrivals)
+
+	// Intervals are at least a nanosecond so the schedule always advances, even if a Poisson draw
+	// rounds down to zero
+	interval := func() time.Duration {
+		if arrival == ArrivalPoisson {
+			return max(time.Duration(localRand.ExpFloat64()/rate*float64(time.Second)), time.Nanosecond)
+		}
+		return max(time.Duration(float64(time.Second)/rate), time.Nanosecond)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	next := time.Now()
+	for {
+		// Emit every arrival that is due, since timers can fire later than requested at high rates
+		for now := time.Now(); !next.After(now); next = next.Add(interval()) {
+			select {
+			case arrivals <- next:
+			default:
+				atomic.AddInt64(dropped, 1)
+			}
+		}
+
+		timer.Reset(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
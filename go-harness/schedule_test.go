@@ -0,0 +1,35 @@
+package harness
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestScheduleAdvancesAtMaxRate(t *testing.T) {
+	// Some Poisson draws round down to zero, but the schedule must keep advancing and stop when the
+	// context is done
+	for _, arrival := range []string{ArrivalFixed, ArrivalPoisson} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		arrivals := make(chan time.Time, scheduleBacklog)
+		var dropped int64
+
+		done := make(chan struct{})
+		go func() {
+			schedule(ctx, rand.New(rand.NewSource(1)), MaxRate, arrival, arrivals, &dropped)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s schedule didn't stop", arrival)
+		}
+		cancel()
+
+		if len(arrivals) != scheduleBacklog || dropped == 0 {
+			t.Errorf("%s: expected a full backlog and dropped arrivals, got %d queued and %d dropped", arrival, len(arrivals), dropped)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
+)
+
+func init() {
+	rootCmd.AddCommand(harness.NewCompareCommand())
+}
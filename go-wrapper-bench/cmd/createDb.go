@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	azurecosmos "github.com/analogrelay/go-rust-interop/go-wrapper"
+	"github.com/spf13/cobra"
+)
+
+// createDbCmd represents the createDb command
+var createDbCmd = &cobra.Command{
+	Use:   "createDb",
+	Short: "Create the benchmark database and container and seed it with sample documents",
+	Long: `Creates the benchmarking database and container through the Go wrapper, then inserts
+sample documents as the seed command does. Existing databases and containers are reused.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runCreateDb(cmd)
+		if err != nil {
+			fmt.Printf("Error creating database: %v\n", err)
+			return
+		}
+	},
+}
+
+func runCreateDb(cmd *cobra.Command) error {
+	opts, err := getSeedOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	containerName, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return fmt.Errorf("failed to get container: %w", err)
+	}
+
+	throughput, err := cmd.Flags().GetInt32("throughput")
+	if err != nil {
+		return fmt.Errorf("failed to get throughput: %w", err)
+	}
+
+	databaseName, err := cmd.Flags().GetString("database")
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+
+	client, err := createCosmosClient(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create Cosmos client: %w", err)
+	}
+	defer client.Close()
+
+	dbClient, err := client.CreateDatabaseWithContext(cmd.Context(), databaseName, nil)
+	if errors.Is(err, azurecosmos.ErrConflict) {
+		fmt.Printf("Database %s already exists\n", databaseName)
+		dbClient, err = client.DatabaseClient(databaseName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+	defer dbClient.Close()
+
+	containerProperties := azurecosmos.ContainerProperties{
+		ID: containerName,
+		PartitionKeyDefinition: azurecosmos.PartitionKeyDefinition{
+			Paths: []string{"/partitionKey"},
+			Kind:  "Hash",
+		},
+	}
+	throughputProperties := azurecosmos.ManualThroughput(throughput)
+	containerClient, err := dbClient.CreateContainerWithContext(cmd.Context(), containerProperties, &throughputProperties)
+	if errors.Is(err, azurecosmos.ErrConflict) {
+		fmt.Printf("Container %s already exists\n", containerName)
+		containerClient, err = dbClient.ContainerClient(containerName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+	defer containerClient.Close()
+
+	fmt.Println("Database and container created successfully.")
+
+	return insertSampleDocuments(cmd.Context(), containerClient, opts)
+}
+
+func init() {
+	rootCmd.AddCommand(createDbCmd)
+
+	addSeedFlags(createDbCmd)
+	createDbCmd.Flags().Int32("throughput", 40000, "Manual throughput (RU/s) to provision on the container")
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
+	azurecosmos "github.com/analogrelay/go-rust-interop/go-wrapper"
+	"github.com/spf13/cobra"
+)
+
+// mixedCmd represents the mixed command
+var mixedCmd = &cobra.Command{
+	Use:   "mixed",
+	Short: "Benchmark a mix of read, write and query operations against CosmosDB",
+	Long: `Performs a benchmark that executes a weighted mix of point reads, creates, upserts,
+replaces, deletes and single-item queries against a CosmosDB container, reporting
+latency statistics for each kind of operation.
+
+Reads, upserts, replaces and queries target the items seeded by createDb or seed, and
+upserts and replaces keep each item's id and partition key, so the seeded items stay
+readable. Creates add new items with run-specific ids, and deletes only remove items
+created earlier in the same run.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runMixedBenchmark(cmd)
+		if err != nil {
+			fmt.Printf("Error running benchmark: %v\n", err)
+			return
+		}
+	},
+}
+
+// mixedItems performs the mixed benchmark's item operations through the Go wrapper
+type mixedItems struct {
+	container *azurecosmos.ContainerClient
+
+	// cancellable passes the harness context to the WithContext calls. Otherwise the benchmark uses
+	// the non-context calls, which don't create a native cancellation token per request.
+	cancellable bool
+}
+
+func (c *mixedItems) ReadItem(ctx context.Context, item harness.ItemKey) error {
+	if !c.cancellable {
+		_, err := c.container.ReadItem(item.ID, item.PartitionKey)
+		return err
+	}
+	_, err := c.container.ReadItemWithContext(ctx, item.ID, item.PartitionKey)
+	return err
+}
+
+func (c *mixedItems) CreateItem(ctx context.Context, item harness.ItemKey, document []byte) error {
+	if !c.cancellable {
+		return c.container.CreateItem(item.PartitionKey, string(document))
+	}
+	return c.container.CreateItemWithContext(ctx, item.PartitionKey, string(document))
+}
+
+func (c *mixedItems) UpsertItem(ctx context.Context, item harness.ItemKey, document []byte) error {
+	if !c.cancellable {
+		return c.container.UpsertItem(item.PartitionKey, string(document))
+	}
+	return c.container.UpsertItemWithContext(ctx, item.PartitionKey, string(document))
+}
+
+func (c *mixedItems) ReplaceItem(ctx context.Context, item harness.ItemKey, document []byte) error {
+	if !c.cancellable {
+		return c.container.ReplaceItem(item.ID, item.PartitionKey, string(document))
+	}
+	return c.container.ReplaceItemWithContext(ctx, item.ID, item.PartitionKey, string(document))
+}
+
+func (c *mixedItems) DeleteItem(ctx context.Context, item harness.ItemKey) error {
+	if !c.cancellable {
+		return c.container.DeleteItem(item.ID, item.PartitionKey)
+	}
+	return c.container.DeleteItemWithContext(ctx, item.ID, item.PartitionKey)
+}
+
+func (c *mixedItems) QueryItem(ctx context.Context, item harness.ItemKey) error {
+	pager, err := c.container.QueryItems(harness.ItemQueryText, []azurecosmos.QueryParameter{{Name: "@id", Value: item.ID}}, item.PartitionKey, nil)
+	if err != nil {
+		return err
+	}
+	if !c.cancellable {
+		ctx = context.Background()
+	}
+	for _, err := range pager.Items(ctx) {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mixedWorkload adds the wrapper's error classification and native resource counts to the item mix
+type mixedWorkload struct {
+	*harness.ItemMix
+}
+
+func (w *mixedWorkload) ClassifyError(err error) string {
+	return classifyError(err)
+}
+
+func (w *mixedWorkload) NativeStats() map[string]int64 {
+	return nativeStats()
+}
+
+func runMixedBenchmark(cmd *cobra.Command) error {
+	// Get configuration
+	cfg, err := harness.ConfigFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	containerName, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return fmt.Errorf("failed to get container: %w", err)
+	}
+
+	spec, err := cmd.Flags().GetString("mix")
+	if err != nil {
+		return fmt.Errorf("failed to get mix: %w", err)
+	}
+	ops, err := harness.ParseItemMix(spec)
+	if err != nil {
+		return err
+	}
+
+	documentSize, err := cmd.Flags().GetInt("document-size")
+	if err != nil {
+		return fmt.Errorf("failed to get document-size: %w", err)
+	}
+	if documentSize < 0 {
+		return fmt.Errorf("document-size must not be negative")
+	}
+
+	cancellable, err := cmd.Flags().GetBool("cancellable")
+	if err != nil {
+		return fmt.Errorf("failed to get cancellable: %w", err)
+	}
+
+	// Create Cosmos client and get container
+	client, err := createCosmosClient(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create Cosmos client: %w", err)
+	}
+	defer client.Close()
+
+	dbClient, err := getTestDbClient(cmd, client)
+	if err != nil {
+		return fmt.Errorf("failed to get database client: %w", err)
+	}
+	defer dbClient.Close()
+
+	containerClient, err := dbClient.ContainerClient(containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container client: %w", err)
+	}
+	defer containerClient.Close()
+
+	fmt.Printf("Starting mixed benchmark...\n")
+	cfg.Print()
+	fmt.Printf("Container: %s\n", containerName)
+	fmt.Printf("Mix: %s\n", harness.FormatMix(ops))
+	fmt.Printf("Cancellable: %t\n", cancellable)
+	fmt.Println()
+
+	workload := &mixedWorkload{harness.NewItemMix(&mixedItems{container: containerClient, cancellable: cancellable}, cfg, ops, documentSize)}
+
+	// Run benchmark
+	results, err := harness.Run(cmd.Context(), cfg, workload)
+	if err != nil {
+		return fmt.Errorf("benchmark failed: %w", err)
+	}
+
+	// Print results
+	results.Print()
+	return harness.WriteReport(cfg.Output, harness.NewReport(cmd, implementationName, workload.Name(), results))
+}
+
+func init() {
+	rootCmd.AddCommand(mixedCmd)
+
+	// Add benchmark-specific flags
+	harness.AddFlags(mixedCmd)
+	mixedCmd.Flags().StringP("container", "c", "RandomDocs", "Container name")
+	mixedCmd.Flags().String("mix", harness.DefaultItemMix, "Relative weights of each operation: read, create, upsert, replace, delete and query")
+	mixedCmd.Flags().Int("document-size", 1024, "Size in bytes of the random data in written documents")
+	mixedCmd.Flags().Bool("cancellable", false, "Pass the run's context to each operation so stopping the run cancels in-flight native requests, at the cost of a native cancellation token per operation")
+}
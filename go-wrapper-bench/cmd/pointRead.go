@@ -3,12 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"math/rand"
-	"runtime"
-	"sync"
-	"sync/atomic"
-	"time"
 
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
 	azurecosmos "github.com/analogrelay/go-rust-interop/go-wrapper"
 	"github.com/spf13/cobra"
 )
@@ -29,38 +25,99 @@ Measures and reports throughput and latency metrics.`,
 	},
 }
 
-type BenchmarkResults struct {
-	TotalOps     int           `json:"totalOps"`
-	ElapsedTime  time.Duration `json:"elapsedTime"`
-	OpsPerSecond float64       `json:"opsPerSecond"`
-	LatencyMs    float64       `json:"latencyMs"`
+// Read modes select which ContainerClient read API the benchmark exercises
+const (
+	readModeString = "string" // ReadItem, converting the payload with C.GoString
+	readModeBytes  = "bytes"  // ReadItemBytes, allocating a new slice per read
+	readModeInto   = "into"   // ReadItemInto, reusing a per-worker buffer
+)
+
+// pointReadWorkload reads random items through the Go wrapper
+type pointReadWorkload struct {
+	container *azurecosmos.ContainerClient
+	keys      []harness.ItemKey
+	readMode  string
+
+	// cancellable passes the harness context to the WithContext reads. Otherwise the benchmark uses
+	// the non-context reads, which don't create a native cancellation token per request.
+	cancellable bool
+}
+
+func (w *pointReadWorkload) Name() string {
+	return "pointRead"
+}
+
+func (w *pointReadWorkload) NewWorker(workerID int) (harness.Operation, error) {
+	return &pointReadWorker{workload: w}, nil
+}
+
+func (w *pointReadWorkload) ClassifyError(err error) string {
+	return classifyError(err)
+}
+
+func (w *pointReadWorkload) NativeStats() map[string]int64 {
+	return nativeStats()
+}
+
+// pointReadWorker holds the buffer reused across reads in readModeInto
+type pointReadWorker struct {
+	workload *pointReadWorkload
+	buf      []byte
+}
+
+func (w *pointReadWorker) Execute(ctx context.Context, key int) error {
+	item := w.workload.keys[key]
+	container := w.workload.container
+
+	var err error
+	if !w.workload.cancellable {
+		switch w.workload.readMode {
+		case readModeBytes:
+			_, err = container.ReadItemBytes(item.ID, item.PartitionKey)
+		case readModeInto:
+			w.buf, err = container.ReadItemInto(w.buf, item.ID, item.PartitionKey)
+		default:
+			_, err = container.ReadItem(item.ID, item.PartitionKey)
+		}
+		return err
+	}
+
+	switch w.workload.readMode {
+	case readModeBytes:
+		_, err = container.ReadItemBytesWithContext(ctx, item.ID, item.PartitionKey)
+	case readModeInto:
+		w.buf, err = container.ReadItemIntoWithContext(ctx, w.buf, item.ID, item.PartitionKey)
+	default:
+		_, err = container.ReadItemWithContext(ctx, item.ID, item.PartitionKey)
+	}
+	return err
 }
 
 func runPointReadBenchmark(cmd *cobra.Command) error {
 	// Get configuration
-	itemCount, err := cmd.Flags().GetInt("item-count")
+	cfg, err := harness.ConfigFromFlags(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to get item-count: %w", err)
+		return err
 	}
 
-	duration, err := cmd.Flags().GetDuration("duration")
+	containerName, err := cmd.Flags().GetString("container")
 	if err != nil {
-		return fmt.Errorf("failed to get duration: %w", err)
+		return fmt.Errorf("failed to get container: %w", err)
 	}
 
-	partitionCount, err := cmd.Flags().GetInt("partition-count")
+	readMode, err := cmd.Flags().GetString("read-mode")
 	if err != nil {
-		return fmt.Errorf("failed to get partition-count: %w", err)
+		return fmt.Errorf("failed to get read-mode: %w", err)
 	}
-
-	workers, err := cmd.Flags().GetInt("workers")
-	if err != nil {
-		return fmt.Errorf("failed to get workers: %w", err)
+	switch readMode {
+	case readModeString, readModeBytes, readModeInto:
+	default:
+		return fmt.Errorf("invalid read-mode %q, expected one of: %s, %s, %s", readMode, readModeString, readModeBytes, readModeInto)
 	}
 
-	containerName, err := cmd.Flags().GetString("container")
+	cancellable, err := cmd.Flags().GetBool("cancellable")
 	if err != nil {
-		return fmt.Errorf("failed to get container: %w", err)
+		return fmt.Errorf("failed to get cancellable: %w", err)
 	}
 
 	// Create Cosmos client and get container
@@ -83,166 +140,36 @@ func runPointReadBenchmark(cmd *cobra.Command) error {
 	defer containerClient.Close()
 
 	fmt.Printf("Starting point read benchmark...\n")
-	fmt.Printf("Item count: %d\n", itemCount)
-	fmt.Printf("Duration: %v\n", duration)
-	fmt.Printf("Partition count: %d\n", partitionCount)
-	fmt.Printf("Workers: %d\n", workers)
+	cfg.Print()
 	fmt.Printf("Container: %s\n", containerName)
+	fmt.Printf("Read mode: %s\n", readMode)
+	fmt.Printf("Cancellable: %t\n", cancellable)
 	fmt.Println()
 
+	workload := &pointReadWorkload{
+		container:   containerClient,
+		keys:        harness.NewItemKeys(cfg.ItemCount, cfg.PartitionCount),
+		readMode:    readMode,
+		cancellable: cancellable,
+	}
+
 	// Run benchmark
-	results, err := executeBenchmark(cmd.Context(), containerClient, itemCount, partitionCount, workers, duration)
+	results, err := harness.Run(cmd.Context(), cfg, workload)
 	if err != nil {
 		return fmt.Errorf("benchmark failed: %w", err)
 	}
 
 	// Print results
-	printResults(results)
-	return nil
-}
-
-func executeBenchmark(ctx context.Context, container *azurecosmos.ContainerClient, itemCount, partitionCount, workers int, duration time.Duration) (*BenchmarkResults, error) {
-	startTime := time.Now()
-	endTime := startTime.Add(duration)
-
-	fmt.Printf("Benchmark started at %v with %d workers\n", startTime.Format("15:04:05.000"), workers)
-
-	// Shared counters for all workers
-	var totalOps int64
-	var totalLatency int64
-
-	// Create a context that will be canceled when the benchmark duration expires
-	benchCtx, cancel := context.WithTimeout(ctx, duration)
-	defer cancel()
-
-	// WaitGroup to wait for all workers to complete
-	var wg sync.WaitGroup
-
-	// Channel to signal workers when to stop (for clean shutdown)
-	stopChan := make(chan struct{})
-
-	// Start workers
-	for i := 0; i < workers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			workerBenchmark(benchCtx, container, itemCount, partitionCount, &totalOps, &totalLatency, stopChan, workerID)
-		}(i)
-	}
-
-	// Progress reporting goroutine
-	progressTicker := time.NewTicker(5 * time.Second)
-	defer progressTicker.Stop()
-
-	go func() {
-		for {
-			select {
-			case <-progressTicker.C:
-				currentOps := atomic.LoadInt64(&totalOps)
-				elapsed := time.Since(startTime)
-				currentOpsPerSec := float64(currentOps) / elapsed.Seconds()
-				remaining := time.Until(endTime)
-				if remaining > 0 {
-					fmt.Printf("Progress: %d ops, %.1f ops/sec, %v remaining\n",
-						currentOps, currentOpsPerSec, remaining.Round(time.Second))
-				}
-			case <-benchCtx.Done():
-				return
-			}
-		}
-	}()
-
-	// Wait for benchmark duration or context cancellation
-	<-benchCtx.Done()
-
-	// Signal all workers to stop
-	close(stopChan)
-
-	// Wait for all workers to finish
-	wg.Wait()
-
-	actualElapsed := time.Since(startTime)
-	finalOps := atomic.LoadInt64(&totalOps)
-	finalLatency := atomic.LoadInt64(&totalLatency)
-
-	if finalOps == 0 {
-		return nil, fmt.Errorf("no operations completed")
-	}
-
-	results := &BenchmarkResults{
-		TotalOps:     int(finalOps),
-		ElapsedTime:  actualElapsed,
-		OpsPerSecond: float64(finalOps) / actualElapsed.Seconds(),
-		LatencyMs:    float64(finalLatency) / float64(finalOps) / 1e6, // Convert to ms
-	}
-
-	return results, nil
-}
-
-func workerBenchmark(ctx context.Context, container *azurecosmos.ContainerClient, itemCount, partitionCount int, totalOps, totalLatency *int64, stopChan chan struct{}, workerID int) {
-	// Create a local random source for this worker to avoid contention
-	localRand := rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerID)))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-stopChan:
-			return
-		default:
-			// Select random item ID
-			itemIndex := localRand.Intn(itemCount)
-			itemID := fmt.Sprintf("item%d", itemIndex)
-			partitionKey := fmt.Sprintf("partition%d", itemIndex%partitionCount)
-
-			// Measure point read latency
-			opStart := time.Now()
-
-			_, err := container.ReadItem(itemID, partitionKey)
-
-			opEnd := time.Now()
-			opLatency := opEnd.Sub(opStart)
-
-			if err != nil {
-				// Log error but don't stop the benchmark for individual failures
-				fmt.Printf("Worker %d: Error reading item %s: %v\n", workerID, itemID, err)
-				continue
-			}
-
-			// Atomically update counters
-			atomic.AddInt64(totalOps, 1)
-			atomic.AddInt64(totalLatency, opLatency.Nanoseconds())
-		}
-	}
-}
-
-func printResults(results *BenchmarkResults) {
-	fmt.Printf("\n=== Benchmark Results ===\n")
-	fmt.Printf("Total ops: %d\n", results.TotalOps)
-	fmt.Printf("Total elapsed time: %v\n", results.ElapsedTime.Round(time.Millisecond))
-	fmt.Printf("Ops/sec: %.2f\n", results.OpsPerSecond)
-	fmt.Printf("Latency (mean): %.2f ms\n", results.LatencyMs)
-	fmt.Printf("========================\n")
-
-	// Print markdown table for README
-	fmt.Printf("\n=== Markdown Table (Point Read Benchmark) ===\n")
-	fmt.Printf("| Implementation | Total Ops | Duration (ms) | Ops/sec | Latency (ms) |\n")
-	fmt.Printf("|---------------|-----------|---------------|---------|--------------|\n")
-	fmt.Printf("| Go Wrapper | %d | %d | %.2f | %.2f |\n",
-		results.TotalOps,
-		results.ElapsedTime.Milliseconds(),
-		results.OpsPerSecond,
-		results.LatencyMs)
-	fmt.Printf("============================================\n")
+	results.Print()
+	return harness.WriteReport(cfg.Output, harness.NewReport(cmd, implementationName, workload.Name(), results))
 }
 
 func init() {
 	rootCmd.AddCommand(pointReadCmd)
 
 	// Add benchmark-specific flags
-	pointReadCmd.Flags().IntP("item-count", "i", 10000, "Total number of items in the database")
-	pointReadCmd.Flags().DurationP("duration", "t", 60*time.Second, "Duration to run the benchmark")
-	pointReadCmd.Flags().IntP("partition-count", "p", 10, "Number of partitions the items are distributed across")
-	pointReadCmd.Flags().IntP("workers", "w", runtime.NumCPU(), "Number of concurrent workers")
+	harness.AddFlags(pointReadCmd)
 	pointReadCmd.Flags().StringP("container", "c", "RandomDocs", "Container name")
+	pointReadCmd.Flags().String("read-mode", readModeString, "Read API to exercise: string (ReadItem), bytes (ReadItemBytes) or into (ReadItemInto with a reused buffer)")
+	pointReadCmd.Flags().Bool("cancellable", false, "Pass the run's context to each read so stopping the run cancels in-flight native requests, at the cost of a native cancellation token per read")
 }
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
 	azurecosmos "github.com/analogrelay/go-rust-interop/go-wrapper"
 	"github.com/spf13/cobra"
 )
@@ -18,11 +21,19 @@ var rootCmd = &cobra.Command{
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+
+	// With AZURECOSMOS_LEAKCHECK=1, report handles that weren't closed before exiting
+	if azurecosmos.LeakCheckEnabled() && azurecosmos.WriteLeakReport(os.Stderr) > 0 && err == nil {
+		os.Exit(2)
+	}
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// implementationName identifies this implementation in benchmark results
+const implementationName = "Go Wrapper"
+
 // Well-known Cosmos DB Emulator key, not a secret.
 const emulatorKey = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
 
@@ -53,3 +64,32 @@ func getTestDbClient(cmd *cobra.Command, client *azurecosmos.CosmosClient) (*azu
 
 	return client.DatabaseClient(databaseName)
 }
+
+// classifyError groups benchmark failures by Cosmos DB status code and sub-status, or by the
+// native error code for failures that never reached the service
+func classifyError(err error) string {
+	var cosmosErr *azurecosmos.CosmosError
+	if !errors.As(err, &cosmosErr) {
+		return "error"
+	}
+	if cosmosErr.StatusCode == 0 {
+		return fmt.Sprintf("code %d", cosmosErr.Code)
+	}
+	return harness.StatusClass(cosmosErr.StatusCode, cosmosErr.SubStatus)
+}
+
+// nativeStats reports the wrapper's native resource counts to the harness
+func nativeStats() map[string]int64 {
+	s := azurecosmos.Stats()
+	return map[string]int64{
+		"clients":            s.Clients,
+		"databases":          s.Databases,
+		"containers":         s.Containers,
+		"queryPagers":        s.QueryPagers,
+		"cancellationTokens": s.CancellationTokens,
+		"buffers":            s.Buffers,
+		"bufferBytes":        s.BufferBytes,
+		"totalBuffers":       s.TotalBuffers,
+		"totalBufferBytes":   s.TotalBufferBytes,
+	}
+}
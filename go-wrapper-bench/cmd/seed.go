@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	harness "github.com/analogrelay/go-rust-interop/go-harness"
+	azurecosmos "github.com/analogrelay/go-rust-interop/go-wrapper"
+	"github.com/spf13/cobra"
+)
+
+// seedCmd represents the seed command
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Insert sample documents into an existing container",
+	Long: `Inserts RandomDocsItem documents into an existing container through the Go wrapper.
+Items are named item0..itemN-1 and spread across partition0..partitionP-1, matching the
+keys used by the pointRead benchmark. Items that already exist are left untouched.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runSeed(cmd)
+		if err != nil {
+			fmt.Printf("Error seeding container: %v\n", err)
+			return
+		}
+	},
+}
+
+type seedOptions struct {
+	itemCount      int
+	partitionCount int
+	documentSize   int
+	concurrency    int
+}
+
+func addSeedFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("item-count", "i", 10000, "Total number of items to insert")
+	cmd.Flags().IntP("partition-count", "p", 10, "Number of partitions to distribute the items across")
+	cmd.Flags().StringP("container", "c", "RandomDocs", "Container name")
+	cmd.Flags().Int("document-size", 1024, "Size in bytes of the random data in each document")
+	cmd.Flags().Int("concurrency", 32, "Number of concurrent inserts")
+}
+
+func getSeedOptions(cmd *cobra.Command) (*seedOptions, error) {
+	itemCount, err := cmd.Flags().GetInt("item-count")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item-count: %w", err)
+	}
+
+	partitionCount, err := cmd.Flags().GetInt("partition-count")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition-count: %w", err)
+	}
+
+	documentSize, err := cmd.Flags().GetInt("document-size")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document-size: %w", err)
+	}
+
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concurrency: %w", err)
+	}
+
+	if itemCount <= 0 || partitionCount <= 0 || concurrency <= 0 || documentSize < 0 {
+		return nil, fmt.Errorf("item-count, partition-count and concurrency must be positive and document-size must not be negative")
+	}
+
+	return &seedOptions{
+		itemCount:      itemCount,
+		partitionCount: partitionCount,
+		documentSize:   documentSize,
+		concurrency:    concurrency,
+	}, nil
+}
+
+func runSeed(cmd *cobra.Command) error {
+	opts, err := getSeedOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	containerName, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return fmt.Errorf("failed to get container: %w", err)
+	}
+
+	client, err := createCosmosClient(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create Cosmos client: %w", err)
+	}
+	defer client.Close()
+
+	dbClient, err := getTestDbClient(cmd, client)
+	if err != nil {
+		return fmt.Errorf("failed to get database client: %w", err)
+	}
+	defer dbClient.Close()
+
+	containerClient, err := dbClient.ContainerClient(containerName)
+	if err != nil {
+		return fmt.Errorf("failed to get container client: %w", err)
+	}
+	defer containerClient.Close()
+
+	return insertSampleDocuments(cmd.Context(), containerClient, opts)
+}
+
+func insertSampleDocuments(ctx context.Context, container *azurecosmos.ContainerClient, opts *seedOptions) error {
+	fmt.Printf("Inserting %d sample documents (%d bytes of data each) across %d partitions with %d concurrent workers...\n",
+		opts.itemCount, opts.documentSize, opts.partitionCount, opts.concurrency)
+
+	jobs := make(chan int, opts.itemCount)
+	results := make(chan error, opts.itemCount)
+	var inserted, skipped int64
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.concurrency; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			localRand := rand.New(rand.NewSource(int64(workerID)))
+			for j := range jobs {
+				item := createRandomDocsItem(localRand, j, opts.partitionCount, opts.documentSize)
+				err := azurecosmos.CreateItemFrom(ctx, container, item.PartitionKey, item)
+				if errors.Is(err, azurecosmos.ErrConflict) {
+					atomic.AddInt64(&skipped, 1)
+					continue
+				}
+				if err != nil {
+					results <- fmt.Errorf("failed to insert %s: %w", item.ID, err)
+					continue
+				}
+				if n := atomic.AddInt64(&inserted, 1); n%1000 == 0 {
+					fmt.Printf("Inserted %d items...\n", n)
+				}
+			}
+		}(w)
+	}
+
+	for i := 0; i < opts.itemCount; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+	close(results)
+
+	errs := []error{}
+	for res := range results {
+		errs = append(errs, res)
+	}
+
+	fmt.Printf("Inserted %d of %d items (%d already existed)\n", inserted, opts.itemCount, skipped)
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+type RandomDocsItem struct {
+	ID           string `json:"id"`
+	PartitionKey string `json:"partitionKey"`
+	Data         string `json:"data"`
+	RandomNumber int    `json:"randomNumber"`
+}
+
+func createRandomDocsItem(localRand *rand.Rand, index, partitionCount, documentSize int) RandomDocsItem {
+	return RandomDocsItem{
+		ID:           fmt.Sprintf("item%d", index),
+		PartitionKey: fmt.Sprintf("partition%d", index%partitionCount),
+		Data:         harness.RandomString(localRand, documentSize),
+		RandomNumber: localRand.Intn(10001),
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	addSeedFlags(seedCmd)
+}
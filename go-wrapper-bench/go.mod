@@ -4,10 +4,16 @@ go 1.25.2
 
 replace github.com/analogrelay/go-rust-interop/go-wrapper => ../go-wrapper
 
-require github.com/analogrelay/go-rust-interop/go-wrapper v0.0.0-00010101000000-000000000000
+replace github.com/analogrelay/go-rust-interop/go-harness => ../go-harness
 
 require (
+	github.com/analogrelay/go-rust-interop/go-harness v0.0.0-00010101000000-000000000000
+	github.com/analogrelay/go-rust-interop/go-wrapper v0.0.0-00010101000000-000000000000
+	github.com/spf13/cobra v1.10.1
+)
+
+require (
+	github.com/goccy/go-json v0.10.5 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.10.1 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
 )
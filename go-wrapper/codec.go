@@ -0,0 +1,74 @@
+package azurecosmos
+
+import (
+	"encoding/json"
+	"fmt"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// Codec converts between Go values and the JSON documents stored in a container.
+// Implement it to plug in a faster JSON library than encoding/json.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default Codec, backed by encoding/json
+var JSONCodec Codec = jsonCodec{}
+
+// FastJSONCodec is a Codec backed by github.com/goccy/go-json, which is compatible with
+// encoding/json, including struct tags and json.Marshaler, but encodes and decodes faster
+var FastJSONCodec Codec = fastJSONCodec{}
+
+// RawCodec passes documents through untouched. It marshals []byte, string and json.RawMessage
+// values, and unmarshals into *[]byte, *string and *json.RawMessage.
+var RawCodec Codec = rawCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type fastJSONCodec struct{}
+
+func (fastJSONCodec) Marshal(v any) ([]byte, error) {
+	return gojson.Marshal(v)
+}
+
+func (fastJSONCodec) Unmarshal(data []byte, v any) error {
+	return gojson.Unmarshal(data, v)
+}
+
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case json.RawMessage:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("raw codec cannot marshal %T", v)
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	switch v := v.(type) {
+	case *[]byte:
+		*v = data
+	case *json.RawMessage:
+		*v = data
+	case *string:
+		*v = string(data)
+	default:
+		return fmt.Errorf("raw codec cannot unmarshal into %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package azurecosmos
+
+/*
+#include "azurecosmos.h"
+*/
+import "C"
+import (
+	"context"
+	"errors"
+)
+
+// callWithContext invokes a native operation with a cancellation token that is cancelled when ctx is done.
+// If ctx can never be cancelled, no token is allocated and the operation receives a nil token.
+// When the native layer reports that it was cancelled, the context's error is returned instead, so
+// callers can match context.Canceled and context.DeadlineExceeded with errors.Is.
+func callWithContext(ctx context.Context, call func(token *C.struct_cosmos_cancellation_token) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if ctx.Done() == nil {
+		return call(nil)
+	}
+
+	var token *C.struct_cosmos_cancellation_token
+	var cerr C.struct_cosmos_error
+
+	code := C.cosmos_cancellation_token_create(&token, &cerr)
+
+	if code != C.COSMOS_ERROR_CODE_SUCCESS {
+		return newCosmosError(cerr)
+	}
+	countHandle(handleCancellationToken)
+	defer func() {
+		C.cosmos_cancellation_token_free(token)
+		releaseHandle(handleCancellationToken, nil, true)
+	}()
+
+	// The token must not be freed while the cancel callback may still be using it
+	cancelled := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		C.cosmos_cancellation_token_cancel(token)
+		close(cancelled)
+	})
+	defer func() {
+		if !stop() {
+			<-cancelled
+		}
+	}()
+
+	err := call(token)
+
+	var cosmosErr *CosmosError
+	if errors.As(err, &cosmosErr) && cosmosErr.Code == C.COSMOS_ERROR_CODE_CANCELLED {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+
+	return err
+}
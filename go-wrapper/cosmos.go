@@ -7,51 +7,29 @@ package azurecosmos
 */
 import "C"
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"unsafe"
 )
 
-// CosmosError wraps a C.cosmos_error and implements the Go error interface
-type CosmosError struct {
-	Code    int32
-	Message string
-}
-
-func (e *CosmosError) Error() string {
-	return fmt.Sprintf("Cosmos error %d: %s", e.Code, e.Message)
-}
-
-// newCosmosError creates a Go error from a C cosmos_error
-func newCosmosError(cerr C.struct_cosmos_error) error {
-	if cerr.code == C.COSMOS_ERROR_CODE_SUCCESS {
-		return nil
-	}
-
-	message := ""
-	if cerr.message != nil {
-		message = C.GoString(cerr.message)
-	}
-
-	return &CosmosError{
-		Code:    int32(cerr.code),
-		Message: message,
-	}
-}
-
 // CosmosClient wraps the native cosmos_client pointer
 type CosmosClient struct {
 	client *C.struct_cosmos_client
+	handle *trackedHandle
 }
 
 // DatabaseClient wraps the native cosmos_database_client pointer
 type DatabaseClient struct {
 	database *C.struct_cosmos_database_client
+	handle   *trackedHandle
 }
 
 // ContainerClient wraps the native cosmos_container_client pointer
 type ContainerClient struct {
 	container *C.struct_cosmos_container_client
+	codec     Codec
+	handle    *trackedHandle
 }
 
 // NewCosmosClientWithKey creates a new CosmosClient using endpoint and key authentication
@@ -71,7 +49,7 @@ func NewCosmosClientWithKey(endpoint, key string) (*CosmosClient, error) {
 		return nil, newCosmosError(cerr)
 	}
 
-	c := &CosmosClient{client: client}
+	c := &CosmosClient{client: client, handle: trackHandle(handleClient)}
 
 	// Set finalizer to ensure cleanup
 	runtime.SetFinalizer(c, (*CosmosClient).finalize)
@@ -79,21 +57,27 @@ func NewCosmosClientWithKey(endpoint, key string) (*CosmosClient, error) {
 	return c, nil
 }
 
-// finalize cleans up the native client
+// finalize cleans up the native client if it was never closed
 func (c *CosmosClient) finalize() {
-	if c.client != nil {
-		C.cosmos_client_free(c.client)
-		c.client = nil
-	}
+	c.release(false)
 }
 
 // Close explicitly releases the native client resources
 func (c *CosmosClient) Close() {
 	runtime.SetFinalizer(c, nil)
-	c.finalize()
+	c.release(true)
 }
 
-// DatabaseClient returns a DatabaseClient for the specified database ID
+func (c *CosmosClient) release(closed bool) {
+	if c.client != nil {
+		C.cosmos_client_free(c.client)
+		c.client = nil
+		releaseHandle(handleClient, c.handle, closed)
+	}
+}
+
+// DatabaseClient returns a DatabaseClient for the specified database ID.
+// This only builds a local handle; it does not contact the service.
 func (c *CosmosClient) DatabaseClient(databaseID string) (*DatabaseClient, error) {
 	if c.client == nil {
 		return nil, fmt.Errorf("client is closed")
@@ -111,7 +95,7 @@ func (c *CosmosClient) DatabaseClient(databaseID string) (*DatabaseClient, error
 		return nil, newCosmosError(cerr)
 	}
 
-	d := &DatabaseClient{database: database}
+	d := &DatabaseClient{database: database, handle: trackHandle(handleDatabase)}
 
 	// Set finalizer to ensure cleanup
 	runtime.SetFinalizer(d, (*DatabaseClient).finalize)
@@ -119,21 +103,27 @@ func (c *CosmosClient) DatabaseClient(databaseID string) (*DatabaseClient, error
 	return d, nil
 }
 
-// finalize cleans up the native database client
+// finalize cleans up the native database client if it was never closed
 func (d *DatabaseClient) finalize() {
-	if d.database != nil {
-		C.cosmos_database_free(d.database)
-		d.database = nil
-	}
+	d.release(false)
 }
 
 // Close explicitly releases the native database client resources
 func (d *DatabaseClient) Close() {
 	runtime.SetFinalizer(d, nil)
-	d.finalize()
+	d.release(true)
 }
 
-// ContainerClient returns a ContainerClient for the specified container ID
+func (d *DatabaseClient) release(closed bool) {
+	if d.database != nil {
+		C.cosmos_database_free(d.database)
+		d.database = nil
+		releaseHandle(handleDatabase, d.handle, closed)
+	}
+}
+
+// ContainerClient returns a ContainerClient for the specified container ID.
+// This only builds a local handle; it does not contact the service.
 func (d *DatabaseClient) ContainerClient(containerID string) (*ContainerClient, error) {
 	if d.database == nil {
 		return nil, fmt.Errorf("database client is closed")
@@ -151,7 +141,7 @@ func (d *DatabaseClient) ContainerClient(containerID string) (*ContainerClient,
 		return nil, newCosmosError(cerr)
 	}
 
-	c := &ContainerClient{container: container}
+	c := &ContainerClient{container: container, handle: trackHandle(handleContainer)}
 
 	// Set finalizer to ensure cleanup
 	runtime.SetFinalizer(c, (*ContainerClient).finalize)
@@ -159,22 +149,46 @@ func (d *DatabaseClient) ContainerClient(containerID string) (*ContainerClient,
 	return c, nil
 }
 
-// finalize cleans up the native container client
+// finalize cleans up the native container client if it was never closed
 func (c *ContainerClient) finalize() {
+	c.release(false)
+}
+
+// Close explicitly releases the native container client resources
+func (c *ContainerClient) Close() {
+	runtime.SetFinalizer(c, nil)
+	c.release(true)
+}
+
+func (c *ContainerClient) release(closed bool) {
 	if c.container != nil {
 		C.cosmos_container_free(c.container)
 		c.container = nil
+		releaseHandle(handleContainer, c.handle, closed)
 	}
 }
 
-// Close explicitly releases the native container client resources
-func (c *ContainerClient) Close() {
-	runtime.SetFinalizer(c, nil)
-	c.finalize()
+// Codec returns the codec used by the typed item helpers, such as ReadItemAs
+func (c *ContainerClient) Codec() Codec {
+	if c.codec == nil {
+		return JSONCodec
+	}
+	return c.codec
+}
+
+// SetCodec sets the codec used by the typed item helpers. Passing nil restores JSONCodec.
+// It must not be called concurrently with operations on the container.
+func (c *ContainerClient) SetCodec(codec Codec) {
+	c.codec = codec
 }
 
 // ReadItem reads an item from the container by ID and partition key, returning the JSON as a string
 func (c *ContainerClient) ReadItem(itemID, partitionKey string) (string, error) {
+	return c.ReadItemWithContext(context.Background(), itemID, partitionKey)
+}
+
+// ReadItemWithContext is like ReadItem, but cancels the native request when ctx is done
+func (c *ContainerClient) ReadItemWithContext(ctx context.Context, itemID, partitionKey string) (string, error) {
 	if c.container == nil {
 		return "", fmt.Errorf("container client is closed")
 	}
@@ -186,12 +200,20 @@ func (c *ContainerClient) ReadItem(itemID, partitionKey string) (string, error)
 	defer C.free(unsafe.Pointer(cPartitionKey))
 
 	var outJson *C.char
-	var cerr C.struct_cosmos_error
 
-	code := C.cosmos_container_read_item(c.container, cPartitionKey, cItemID, &outJson, &cerr)
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
 
-	if code != C.COSMOS_ERROR_CODE_SUCCESS {
-		return "", newCosmosError(cerr)
+		code := C.cosmos_container_read_item_cancellable(c.container, cPartitionKey, cItemID, token, &outJson, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		receivedString(outJson)
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
 	if outJson == nil {
@@ -199,8 +221,219 @@ func (c *ContainerClient) ReadItem(itemID, partitionKey string) (string, error)
 	}
 
 	// Convert C string to Go string and free the C memory
-	result := C.GoString(outJson)
-	C.cosmos_string_free(outJson)
+	result := nativeString(outJson)
 
 	return result, nil
 }
+
+// ReadItemBytes reads an item from the container by ID and partition key, returning the JSON as a byte slice.
+// Unlike ReadItem, the payload is copied out of native memory exactly once.
+func (c *ContainerClient) ReadItemBytes(itemID, partitionKey string) ([]byte, error) {
+	return c.ReadItemIntoWithContext(context.Background(), nil, itemID, partitionKey)
+}
+
+// ReadItemBytesWithContext is like ReadItemBytes, but cancels the native request when ctx is done
+func (c *ContainerClient) ReadItemBytesWithContext(ctx context.Context, itemID, partitionKey string) ([]byte, error) {
+	return c.ReadItemIntoWithContext(ctx, nil, itemID, partitionKey)
+}
+
+// ReadItemInto reads an item from the container by ID and partition key into buf, growing it if it is
+// too small, and returns the filled slice. Reusing the returned slice across calls (or taking buffers
+// from a pool) avoids allocating for each read.
+func (c *ContainerClient) ReadItemInto(buf []byte, itemID, partitionKey string) ([]byte, error) {
+	return c.ReadItemIntoWithContext(context.Background(), buf, itemID, partitionKey)
+}
+
+// ReadItemIntoWithContext is like ReadItemInto, but cancels the native request when ctx is done
+func (c *ContainerClient) ReadItemIntoWithContext(ctx context.Context, buf []byte, itemID, partitionKey string) ([]byte, error) {
+	if c.container == nil {
+		return buf, fmt.Errorf("container client is closed")
+	}
+
+	cItemID := C.CString(itemID)
+	defer C.free(unsafe.Pointer(cItemID))
+
+	cPartitionKey := C.CString(partitionKey)
+	defer C.free(unsafe.Pointer(cPartitionKey))
+
+	var outData *C.uint8_t
+	var outLen C.size_t
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_read_item_bytes_cancellable(c.container, cPartitionKey, cItemID, token, &outData, &outLen, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		receivedBytes(outData, outLen)
+		return nil
+	})
+	if err != nil {
+		return buf, err
+	}
+
+	if outData == nil {
+		return buf, fmt.Errorf("received null JSON response")
+	}
+
+	// Copy the native payload into buf and free the C memory
+	buf = append(buf[:0], nativeBytes(outData, outLen)...)
+	freeNativeBytes(outData, outLen)
+
+	return buf, nil
+}
+
+// CreateItem creates a new item in the container from the provided JSON document
+func (c *ContainerClient) CreateItem(partitionKey, itemJson string) error {
+	return c.CreateItemWithContext(context.Background(), partitionKey, itemJson)
+}
+
+// CreateItemWithContext is like CreateItem, but cancels the native request when ctx is done
+func (c *ContainerClient) CreateItemWithContext(ctx context.Context, partitionKey, itemJson string) error {
+	if c.container == nil {
+		return fmt.Errorf("container client is closed")
+	}
+
+	cPartitionKey := C.CString(partitionKey)
+	defer C.free(unsafe.Pointer(cPartitionKey))
+
+	cItemJson := C.CString(itemJson)
+	defer C.free(unsafe.Pointer(cItemJson))
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_create_item_cancellable(c.container, cPartitionKey, cItemJson, token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
+
+// UpsertItem creates the item, or replaces it if an item with the same ID already exists
+func (c *ContainerClient) UpsertItem(partitionKey, itemJson string) error {
+	return c.UpsertItemWithContext(context.Background(), partitionKey, itemJson)
+}
+
+// UpsertItemWithContext is like UpsertItem, but cancels the native request when ctx is done
+func (c *ContainerClient) UpsertItemWithContext(ctx context.Context, partitionKey, itemJson string) error {
+	if c.container == nil {
+		return fmt.Errorf("container client is closed")
+	}
+
+	cPartitionKey := C.CString(partitionKey)
+	defer C.free(unsafe.Pointer(cPartitionKey))
+
+	cItemJson := C.CString(itemJson)
+	defer C.free(unsafe.Pointer(cItemJson))
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_upsert_item_cancellable(c.container, cPartitionKey, cItemJson, token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
+
+// ReplaceItem replaces an existing item, identified by ID and partition key, with the provided JSON document
+func (c *ContainerClient) ReplaceItem(itemID, partitionKey, itemJson string) error {
+	return c.ReplaceItemWithContext(context.Background(), itemID, partitionKey, itemJson)
+}
+
+// ReplaceItemWithContext is like ReplaceItem, but cancels the native request when ctx is done
+func (c *ContainerClient) ReplaceItemWithContext(ctx context.Context, itemID, partitionKey, itemJson string) error {
+	if c.container == nil {
+		return fmt.Errorf("container client is closed")
+	}
+
+	cItemID := C.CString(itemID)
+	defer C.free(unsafe.Pointer(cItemID))
+
+	cPartitionKey := C.CString(partitionKey)
+	defer C.free(unsafe.Pointer(cPartitionKey))
+
+	cItemJson := C.CString(itemJson)
+	defer C.free(unsafe.Pointer(cItemJson))
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_replace_item_cancellable(c.container, cPartitionKey, cItemID, cItemJson, token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
+
+// DeleteItem deletes an item from the container by ID and partition key
+func (c *ContainerClient) DeleteItem(itemID, partitionKey string) error {
+	return c.DeleteItemWithContext(context.Background(), itemID, partitionKey)
+}
+
+// DeleteItemWithContext is like DeleteItem, but cancels the native request when ctx is done
+func (c *ContainerClient) DeleteItemWithContext(ctx context.Context, itemID, partitionKey string) error {
+	if c.container == nil {
+		return fmt.Errorf("container client is closed")
+	}
+
+	cItemID := C.CString(itemID)
+	defer C.free(unsafe.Pointer(cItemID))
+
+	cPartitionKey := C.CString(partitionKey)
+	defer C.free(unsafe.Pointer(cPartitionKey))
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_delete_item_cancellable(c.container, cPartitionKey, cItemID, token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
+
+// PatchItem applies a JSON patch document to an item, identified by ID and partition key.
+// The patch document uses the Cosmos DB patch format, e.g.
+// `{"operations":[{"op":"set","path":"/randomNumber","value":42}]}`.
+func (c *ContainerClient) PatchItem(itemID, partitionKey, patchJson string) error {
+	return c.PatchItemWithContext(context.Background(), itemID, partitionKey, patchJson)
+}
+
+// PatchItemWithContext is like PatchItem, but cancels the native request when ctx is done
+func (c *ContainerClient) PatchItemWithContext(ctx context.Context, itemID, partitionKey, patchJson string) error {
+	if c.container == nil {
+		return fmt.Errorf("container client is closed")
+	}
+
+	cItemID := C.CString(itemID)
+	defer C.free(unsafe.Pointer(cItemID))
+
+	cPartitionKey := C.CString(partitionKey)
+	defer C.free(unsafe.Pointer(cPartitionKey))
+
+	cPatchJson := C.CString(patchJson)
+	defer C.free(unsafe.Pointer(cPatchJson))
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_patch_item_cancellable(c.container, cPartitionKey, cItemID, cPatchJson, token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,206 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/analogrelay/go-rust-interop/go-fakecosmos"
+	azurecosmos "github.com/analogrelay/go-rust-interop/go-wrapper"
+)
+
+// These tests exercise the wrapper and native library end-to-end against the in-memory fake
+// server. Run them from this directory with: go test -tags e2e ./...
+
+type testItem struct {
+	ID           string `json:"id"`
+	PartitionKey string `json:"partitionKey"`
+	Value        int    `json:"value"`
+}
+
+func newTestContainer(t *testing.T) *azurecosmos.ContainerClient {
+	server, err := fakecosmos.NewServer(fakecosmos.Options{Key: fakecosmos.EmulatorKey})
+	if err != nil {
+		t.Fatalf("failed to create fake server: %v", err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	client, err := azurecosmos.NewCosmosClientWithKey(ts.URL, fakecosmos.EmulatorKey)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	ctx := context.Background()
+	db, err := client.CreateDatabaseWithContext(ctx, "testdb", nil)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	container, err := db.CreateContainerWithContext(ctx, azurecosmos.ContainerProperties{
+		ID:                     "items",
+		PartitionKeyDefinition: azurecosmos.PartitionKeyDefinition{Paths: []string{"/partitionKey"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	t.Cleanup(container.Close)
+	return container
+}
+
+func TestItemRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	container := newTestContainer(t)
+
+	item := testItem{ID: "item1", PartitionKey: "p1", Value: 42}
+	if err := azurecosmos.CreateItemFrom(ctx, container, item.PartitionKey, item); err != nil {
+		t.Fatalf("CreateItem failed: %v", err)
+	}
+	if err := azurecosmos.CreateItemFrom(ctx, container, item.PartitionKey, item); !errors.Is(err, azurecosmos.ErrConflict) {
+		t.Fatalf("expected ErrConflict creating a duplicate item, got %v", err)
+	}
+
+	got, err := azurecosmos.ReadItemAs[testItem](ctx, container, item.ID, item.PartitionKey)
+	if err != nil {
+		t.Fatalf("ReadItem failed: %v", err)
+	}
+	if got != item {
+		t.Fatalf("expected %+v, got %+v", item, got)
+	}
+
+	if err := container.DeleteItem(item.ID, item.PartitionKey); err != nil {
+		t.Fatalf("DeleteItem failed: %v", err)
+	}
+	if _, err := container.ReadItem(item.ID, item.PartitionKey); !errors.Is(err, azurecosmos.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound reading a deleted item, got %v", err)
+	}
+}
+
+func TestFastJSONCodec(t *testing.T) {
+	ctx := context.Background()
+	container := newTestContainer(t)
+	container.SetCodec(azurecosmos.FastJSONCodec)
+
+	item := testItem{ID: "item1", PartitionKey: "p1", Value: 42}
+	if err := azurecosmos.CreateItemFrom(ctx, container, item.PartitionKey, item); err != nil {
+		t.Fatalf("CreateItem failed: %v", err)
+	}
+	got, err := azurecosmos.ReadItemAs[testItem](ctx, container, item.ID, item.PartitionKey)
+	if err != nil {
+		t.Fatalf("ReadItem failed: %v", err)
+	}
+	if got != item {
+		t.Fatalf("expected %+v, got %+v", item, got)
+	}
+}
+
+func TestQueryItems(t *testing.T) {
+	ctx := context.Background()
+	container := newTestContainer(t)
+
+	for i, id := range []string{"a", "b", "c", "d"} {
+		item := testItem{ID: id, PartitionKey: "p1", Value: i}
+		if err := azurecosmos.CreateItemFrom(ctx, container, item.PartitionKey, item); err != nil {
+			t.Fatalf("CreateItem failed: %v", err)
+		}
+	}
+
+	var ids []string
+	query := "SELECT * FROM c WHERE c.value >= @min"
+	params := []azurecosmos.QueryParameter{{Name: "@min", Value: 1}}
+	for item, err := range azurecosmos.QueryItemsAs[testItem](ctx, container, query, params, "p1", &azurecosmos.QueryOptions{MaxItemCount: 2}) {
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+	if len(ids) != 3 || ids[0] != "b" || ids[2] != "d" {
+		t.Fatalf("expected items b, c and d, got %v", ids)
+	}
+
+	if _, err := container.QueryItems(query, params, "p1", &azurecosmos.QueryOptions{MaxItemCount: math.MaxInt32 + 1}); err == nil {
+		t.Fatal("expected an error for a MaxItemCount that doesn't fit in 32 bits")
+	}
+}
+
+func TestNativeStats(t *testing.T) {
+	before := azurecosmos.Stats()
+
+	t.Run("use", func(t *testing.T) {
+		ctx := context.Background()
+		container := newTestContainer(t)
+
+		if during := azurecosmos.Stats(); during.Clients != before.Clients+1 || during.Containers != before.Containers+1 {
+			t.Errorf("expected a live client and container, got %+v", during)
+		}
+
+		item := testItem{ID: "item1", PartitionKey: "p1", Value: 42}
+		if err := azurecosmos.CreateItemFrom(ctx, container, item.PartitionKey, item); err != nil {
+			t.Fatalf("CreateItem failed: %v", err)
+		}
+		if _, err := container.ReadItemBytes(item.ID, item.PartitionKey); err != nil {
+			t.Fatalf("ReadItem failed: %v", err)
+		}
+	})
+
+	// The subtest's cleanups closed every handle it created
+	after := azurecosmos.Stats()
+	if after.LiveHandles() != before.LiveHandles() || after.Buffers != 0 || after.BufferBytes != 0 {
+		t.Errorf("expected every handle and buffer to be freed, got %+v", after)
+	}
+	if after.TotalBuffers <= before.TotalBuffers || after.TotalBufferBytes <= before.TotalBufferBytes {
+		t.Errorf("expected the read to be counted, got %+v", after)
+	}
+}
+
+func TestLeakCheck(t *testing.T) {
+	azurecosmos.SetLeakCheck(true)
+	defer azurecosmos.SetLeakCheck(false)
+
+	server, err := fakecosmos.NewServer(fakecosmos.Options{Key: fakecosmos.EmulatorKey})
+	if err != nil {
+		t.Fatalf("failed to create fake server: %v", err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := azurecosmos.NewCosmosClientWithKey(ts.URL, fakecosmos.EmulatorKey)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	// Create a database client and never close it
+	db, err := client.DatabaseClient("testdb")
+	if err != nil {
+		t.Fatalf("failed to create database client: %v", err)
+	}
+
+	leaks := azurecosmos.Leaks()
+	if len(leaks) != 2 || leaks[0].Finalized || leaks[1].Finalized {
+		t.Fatalf("expected an open client and database client, got %+v", leaks)
+	}
+	for _, leak := range leaks {
+		if !strings.Contains(leak.Stack, "TestLeakCheck") {
+			t.Errorf("expected the stack of the %s to include the test, got:\n%s", leak.Type, leak.Stack)
+		}
+	}
+
+	// Once it is unreachable, the finalizer frees the database client
+	runtime.KeepAlive(db)
+	var report strings.Builder
+	if n := azurecosmos.WriteLeakReport(&report); n != 2 {
+		t.Fatalf("expected 2 leaked handles, got %d:\n%s", n, report.String())
+	}
+	if !strings.Contains(report.String(), "1 DatabaseClient (finalized without Close)") {
+		t.Errorf("expected the database client to be reported as finalized, got:\n%s", report.String())
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/analogrelay/go-rust-interop/go-wrapper/e2e
+
+go 1.25.2
+
+replace github.com/analogrelay/go-rust-interop/go-wrapper => ../
+
+replace github.com/analogrelay/go-rust-interop/go-fakecosmos => ../../go-fakecosmos
+
+require (
+	github.com/analogrelay/go-rust-interop/go-fakecosmos v0.0.0-00010101000000-000000000000
+	github.com/analogrelay/go-rust-interop/go-wrapper v0.0.0-00010101000000-000000000000
+)
+
+require github.com/goccy/go-json v0.10.5 // indirect
@@ -0,0 +1,139 @@
+package azurecosmos
+
+/*
+#include "azurecosmos.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors for common Cosmos DB failures. A *CosmosError matches these with errors.Is.
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrRequestTimeout     = errors.New("request timeout")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrThrottled          = errors.New("throttled")
+	ErrServiceUnavailable = errors.New("service unavailable")
+)
+
+// CosmosError wraps a C.cosmos_error and implements the Go error interface
+type CosmosError struct {
+	// Code is the native cosmos_error_code
+	Code    int32
+	Message string
+
+	// StatusCode is the HTTP status code of the failed request, or 0 if the
+	// error did not come from the service (e.g. an invalid argument)
+	StatusCode int
+	// SubStatus is the Cosmos DB sub-status code (x-ms-substatus), or 0 if none was returned
+	SubStatus int
+	// ActivityID identifies the request in service-side diagnostics
+	ActivityID string
+	// RetryAfter is how long the service asked the client to wait before retrying
+	RetryAfter time.Duration
+	// RequestCharge is the number of request units consumed by the failed request
+	RequestCharge float64
+}
+
+func (e *CosmosError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("Cosmos error %d: %s", e.Code, e.Message)
+	}
+	if e.ActivityID == "" {
+		return fmt.Sprintf("Cosmos error %d (HTTP %d.%d): %s", e.Code, e.StatusCode, e.SubStatus, e.Message)
+	}
+	return fmt.Sprintf("Cosmos error %d (HTTP %d.%d, activity %s): %s", e.Code, e.StatusCode, e.SubStatus, e.ActivityID, e.Message)
+}
+
+// Is reports whether target is the sentinel error matching this error's status code
+func (e *CosmosError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusRequestTimeout:
+		return target == ErrRequestTimeout
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusPreconditionFailed:
+		return target == ErrPreconditionFailed
+	case http.StatusTooManyRequests:
+		return target == ErrThrottled
+	case http.StatusServiceUnavailable:
+		return target == ErrServiceUnavailable
+	}
+	return false
+}
+
+// newCosmosError creates a Go error from a C cosmos_error
+func newCosmosError(cerr C.struct_cosmos_error) error {
+	if cerr.code == C.COSMOS_ERROR_CODE_SUCCESS {
+		return nil
+	}
+
+	message := ""
+	if cerr.message != nil {
+		message = C.GoString(cerr.message)
+	}
+
+	activityID := ""
+	if cerr.activity_id != nil {
+		activityID = C.GoString(cerr.activity_id)
+	}
+
+	statusCode := int(cerr.status_code)
+	if statusCode == 0 {
+		// Older native builds only report the error code
+		statusCode = statusCodeFromErrorCode(int32(cerr.code))
+	}
+
+	return &CosmosError{
+		Code:          int32(cerr.code),
+		Message:       message,
+		StatusCode:    statusCode,
+		SubStatus:     int(cerr.sub_status),
+		ActivityID:    activityID,
+		RetryAfter:    time.Duration(cerr.retry_after_ms) * time.Millisecond,
+		RequestCharge: float64(cerr.request_charge),
+	}
+}
+
+// statusCodeFromErrorCode maps the HTTP-derived cosmos_error_code values to their status code
+func statusCodeFromErrorCode(code int32) int {
+	switch code {
+	case C.COSMOS_ERROR_CODE_BAD_REQUEST:
+		return http.StatusBadRequest
+	case C.COSMOS_ERROR_CODE_UNAUTHORIZED:
+		return http.StatusUnauthorized
+	case C.COSMOS_ERROR_CODE_FORBIDDEN:
+		return http.StatusForbidden
+	case C.COSMOS_ERROR_CODE_NOT_FOUND:
+		return http.StatusNotFound
+	case C.COSMOS_ERROR_CODE_REQUEST_TIMEOUT:
+		return http.StatusRequestTimeout
+	case C.COSMOS_ERROR_CODE_CONFLICT:
+		return http.StatusConflict
+	case C.COSMOS_ERROR_CODE_PRECONDITION_FAILED:
+		return http.StatusPreconditionFailed
+	case C.COSMOS_ERROR_CODE_TOO_MANY_REQUESTS:
+		return http.StatusTooManyRequests
+	case C.COSMOS_ERROR_CODE_INTERNAL_SERVER_ERROR:
+		return http.StatusInternalServerError
+	case C.COSMOS_ERROR_CODE_SERVICE_UNAVAILABLE:
+		return http.StatusServiceUnavailable
+	}
+	return 0
+}
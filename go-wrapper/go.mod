@@ -1,3 +1,5 @@
 module github.com/analogrelay/go-rust-interop/go-wrapper
 
 go 1.25.2
+
+require github.com/goccy/go-json v0.10.5
@@ -0,0 +1,256 @@
+package azurecosmos
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LeakCheckEnv is the environment variable that enables handle leak detection when set to 1
+const LeakCheckEnv = "AZURECOSMOS_LEAKCHECK"
+
+// maxLeakStackDepth limits the frames recorded for each handle
+const maxLeakStackDepth = 32
+
+// maxFinalizedStacks limits the distinct creation stacks remembered for finalized handles. Handles
+// finalized after that from other places are only counted by type.
+const maxFinalizedStacks = 100
+
+// leakCheck is set while handle leak detection is enabled
+var leakCheck atomic.Bool
+
+func init() {
+	leakCheck.Store(os.Getenv(LeakCheckEnv) == "1")
+}
+
+// SetLeakCheck enables or disables handle leak detection. It is also enabled by setting
+// AZURECOSMOS_LEAKCHECK=1. While it is enabled, the stack that created each client and query pager
+// is recorded, so handles that are never closed can be reported by Leaks and WriteLeakReport. Only
+// handles created while it is enabled are tracked. Cancellation tokens are freed before the request
+// that created them returns, so they are only counted in Stats, and requests themselves aren't slowed.
+//
+// Go has no exit hooks, so the wrapper can't report leaks when the process exits on its own.
+// Programs that enable leak detection must call WriteLeakReport before they exit, e.g. at the end
+// of main, after every client has been closed.
+func SetLeakCheck(enabled bool) {
+	leakCheck.Store(enabled)
+}
+
+// LeakCheckEnabled reports whether handle leak detection is enabled
+func LeakCheckEnabled() bool {
+	return leakCheck.Load()
+}
+
+func (k handleKind) String() string {
+	switch k {
+	case handleClient:
+		return "CosmosClient"
+	case handleDatabase:
+		return "DatabaseClient"
+	case handleContainer:
+		return "ContainerClient"
+	case handleQueryPager:
+		return "QueryPager"
+	case handleCancellationToken:
+		return "cancellation token"
+	}
+	return fmt.Sprintf("handle kind %d", int(k))
+}
+
+// trackedHandle records where a native handle was created
+type trackedHandle struct {
+	kind    handleKind
+	created time.Time
+	stack   []uintptr
+}
+
+// finalizedKey groups finalized handles by type and creation stack. The stack is empty for the
+// handles counted after maxFinalizedStacks was reached.
+type finalizedKey struct {
+	kind  handleKind
+	stack string
+}
+
+// finalizedGroup counts the handles of one finalizedKey that were freed by a finalizer because
+// they were never closed
+type finalizedGroup struct {
+	stack  []uintptr
+	count  int
+	oldest time.Time
+}
+
+var (
+	leakMu          sync.Mutex
+	openHandles     = map[*trackedHandle]struct{}{}
+	finalizedGroups = map[finalizedKey]*finalizedGroup{}
+)
+
+// recordHandle records the caller's stack for a new handle
+func recordHandle(kind handleKind) *trackedHandle {
+	pcs := make([]uintptr, maxLeakStackDepth)
+	// Skip runtime.Callers, recordHandle and trackHandle
+	n := runtime.Callers(3, pcs)
+	h := &trackedHandle{kind: kind, created: time.Now(), stack: pcs[:n]}
+
+	leakMu.Lock()
+	openHandles[h] = struct{}{}
+	leakMu.Unlock()
+	return h
+}
+
+// forgetHandle removes a freed handle, counting it if it was never closed
+func forgetHandle(h *trackedHandle, closed bool) {
+	leakMu.Lock()
+	defer leakMu.Unlock()
+	delete(openHandles, h)
+	if closed {
+		return
+	}
+
+	key := finalizedKey{kind: h.kind, stack: fmt.Sprint(h.stack)}
+	group, ok := finalizedGroups[key]
+	if !ok && len(finalizedGroups) >= maxFinalizedStacks {
+		key = finalizedKey{kind: h.kind}
+		group, ok = finalizedGroups[key]
+	}
+	if !ok {
+		group = &finalizedGroup{oldest: h.created}
+		if key.stack != "" {
+			group.stack = h.stack
+		}
+		finalizedGroups[key] = group
+	}
+	group.count++
+	if h.created.Before(group.oldest) {
+		group.oldest = h.created
+	}
+}
+
+// HandleLeak describes handles of one type, created at the same place, that were finalized without
+// being closed or are still open
+type HandleLeak struct {
+	Type string
+	// Finalized is set for handles freed by the garbage collector, and unset for handles still open
+	Finalized bool
+	Count     int
+	// Oldest is when the oldest of the handles was created
+	Oldest time.Time
+	// Stack is the stack trace of the code that created the handles. It is empty for finalized
+	// handles counted after too many distinct stacks were recorded.
+	Stack string
+}
+
+// Leaks returns the handles that were finalized without being closed or are still open, grouped by
+// where they were created, most numerous first. It runs a garbage collection first, so that handles
+// that are no longer reachable are finalized. It returns nil if leak detection is disabled.
+func Leaks() []HandleLeak {
+	if !LeakCheckEnabled() {
+		return nil
+	}
+	runFinalizers()
+
+	leakMu.Lock()
+	defer leakMu.Unlock()
+
+	groups := map[string]*HandleLeak{}
+	var leaks []*HandleLeak
+	add := func(kind handleKind, finalized bool, pcs []uintptr, count int, created time.Time) {
+		stack := formatStack(pcs)
+		key := fmt.Sprintf("%v/%v/%s", kind, finalized, stack)
+		leak, ok := groups[key]
+		if !ok {
+			leak = &HandleLeak{Type: kind.String(), Finalized: finalized, Oldest: created, Stack: stack}
+			groups[key] = leak
+			leaks = append(leaks, leak)
+		}
+		leak.Count += count
+		if created.Before(leak.Oldest) {
+			leak.Oldest = created
+		}
+	}
+	for key, group := range finalizedGroups {
+		add(key.kind, true, group.stack, group.count, group.oldest)
+	}
+	for h := range openHandles {
+		add(h.kind, false, h.stack, 1, h.created)
+	}
+
+	sort.SliceStable(leaks, func(i, j int) bool {
+		if leaks[i].Count != leaks[j].Count {
+			return leaks[i].Count > leaks[j].Count
+		}
+		return leaks[i].Oldest.Before(leaks[j].Oldest)
+	})
+	result := make([]HandleLeak, len(leaks))
+	for i, leak := range leaks {
+		result[i] = *leak
+	}
+	return result
+}
+
+// WriteLeakReport writes the handles returned by Leaks to w with their creation stacks, and returns
+// how many handles were leaked
+func WriteLeakReport(w io.Writer) int {
+	leaks := Leaks()
+	total := 0
+	for _, leak := range leaks {
+		total += leak.Count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	fmt.Fprintf(w, "%d native handles were not closed:\n", total)
+	for _, leak := range leaks {
+		state := "still open"
+		if leak.Finalized {
+			state = "finalized without Close"
+		}
+		stack := leak.Stack
+		if stack == "" {
+			stack = "\t<no stack recorded>\n"
+		}
+		fmt.Fprintf(w, "\n%d %s (%s), oldest created at %s:\n%s", leak.Count, leak.Type, state, leak.Oldest.Format("15:04:05.000"), stack)
+	}
+	return total
+}
+
+// runFinalizers runs a garbage collection and waits for the finalizers it queued to run
+func runFinalizers() {
+	runtime.GC()
+
+	// Finalizers run one at a time on a single goroutine, so once a sentinel queued by a
+	// second collection has run, so have the ones queued before it. The sentinel holds a pointer
+	// so it isn't batched by the tiny allocator, whose objects may never be finalized.
+	done := make(chan struct{})
+	runtime.SetFinalizer(&struct{ _ *int }{}, func(any) { close(done) })
+	runtime.GC()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+	}
+}
+
+// formatStack formats a recorded stack like a goroutine trace, one "function\n\tfile:line" per
+// frame, or returns "" if no stack was recorded
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package azurecosmos
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFinalizedHandlesAreBounded(t *testing.T) {
+	SetLeakCheck(true)
+	defer SetLeakCheck(false)
+	defer func() {
+		leakMu.Lock()
+		finalizedGroups = map[finalizedKey]*finalizedGroup{}
+		leakMu.Unlock()
+	}()
+
+	// Give every handle a different stack, so only the first maxFinalizedStacks are recorded
+	total := maxFinalizedStacks + 50
+	for i := range total {
+		forgetHandle(&trackedHandle{kind: handleQueryPager, created: time.Now(), stack: []uintptr{uintptr(i + 1)}}, false)
+	}
+	forgetHandle(&trackedHandle{kind: handleQueryPager, created: time.Now()}, true)
+
+	leakMu.Lock()
+	groups := len(finalizedGroups)
+	leakMu.Unlock()
+	if groups != maxFinalizedStacks+1 {
+		t.Errorf("expected %d stacks and one count without a stack, got %d groups", maxFinalizedStacks, groups)
+	}
+
+	count := 0
+	unrecorded := 0
+	for _, leak := range Leaks() {
+		if leak.Type != "QueryPager" || !leak.Finalized {
+			continue
+		}
+		count += leak.Count
+		if leak.Stack == "" {
+			unrecorded += leak.Count
+		}
+	}
+	if count != total || unrecorded != total-maxFinalizedStacks {
+		t.Errorf("expected %d finalized pagers, %d without a stack, got %d and %d", total, total-maxFinalizedStacks, count, unrecorded)
+	}
+
+	var report strings.Builder
+	WriteLeakReport(&report)
+	if !strings.Contains(report.String(), "<no stack recorded>") {
+		t.Errorf("expected the handles without a stack to be reported as such, got:\n%s", report.String())
+	}
+}
+
+func TestFormatEmptyStack(t *testing.T) {
+	if stack := formatStack(nil); stack != "" {
+		t.Errorf("expected an empty stack to format as \"\", got %q", stack)
+	}
+}
@@ -0,0 +1,459 @@
+package azurecosmos
+
+/*
+#include <stdlib.h>
+#include "azurecosmos.h"
+*/
+import "C"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// DatabaseProperties describes a database, as returned by the service
+type DatabaseProperties struct {
+	ID           string `json:"id"`
+	ResourceID   string `json:"_rid,omitempty"`
+	ETag         string `json:"_etag,omitempty"`
+	LastModified int64  `json:"_ts,omitempty"`
+}
+
+// PartitionKeyDefinition describes how items in a container are partitioned
+type PartitionKeyDefinition struct {
+	Paths []string `json:"paths"`
+	// Kind is "Hash" (the default) or "MultiHash" for hierarchical partition keys
+	Kind    string `json:"kind,omitempty"`
+	Version int    `json:"version,omitempty"`
+}
+
+// ContainerProperties describes a container
+type ContainerProperties struct {
+	ID                     string                 `json:"id"`
+	PartitionKeyDefinition PartitionKeyDefinition `json:"partitionKey"`
+	// DefaultTimeToLive is the default item TTL in seconds; -1 enables TTL without a default
+	DefaultTimeToLive *int   `json:"defaultTtl,omitempty"`
+	ResourceID        string `json:"_rid,omitempty"`
+	ETag              string `json:"_etag,omitempty"`
+	LastModified      int64  `json:"_ts,omitempty"`
+}
+
+// ThroughputProperties describes the throughput provisioned on a database or container
+type ThroughputProperties struct {
+	// Throughput is the provisioned RU/s, or the maximum RU/s when Autoscale is set
+	Throughput int32
+	Autoscale  bool
+}
+
+// ManualThroughput returns properties for a fixed number of RU/s
+func ManualThroughput(throughput int32) ThroughputProperties {
+	return ThroughputProperties{Throughput: throughput}
+}
+
+// AutoscaleThroughput returns properties for autoscale throughput up to maxThroughput RU/s
+func AutoscaleThroughput(maxThroughput int32) ThroughputProperties {
+	return ThroughputProperties{Throughput: maxThroughput, Autoscale: true}
+}
+
+// toNative converts throughput properties to the native representation; nil means no dedicated throughput
+func (t *ThroughputProperties) toNative() *C.struct_cosmos_throughput {
+	if t == nil {
+		return nil
+	}
+	return &C.struct_cosmos_throughput{
+		throughput: C.int32_t(t.Throughput),
+		autoscale:  C.bool(t.Autoscale),
+	}
+}
+
+// decodeNativeJson decodes a JSON string returned by the native layer into v and frees the C memory
+func decodeNativeJson(outJson *C.char, v any) error {
+	if outJson == nil {
+		return fmt.Errorf("received null JSON response")
+	}
+
+	data := nativeString(outJson)
+
+	return json.Unmarshal([]byte(data), v)
+}
+
+// CreateDatabase creates a database and returns a DatabaseClient for it.
+// Pass nil throughput to create a database without shared throughput.
+func (c *CosmosClient) CreateDatabase(databaseID string, throughput *ThroughputProperties) (*DatabaseClient, error) {
+	return c.CreateDatabaseWithContext(context.Background(), databaseID, throughput)
+}
+
+// CreateDatabaseWithContext is like CreateDatabase, but cancels the native request when ctx is done
+func (c *CosmosClient) CreateDatabaseWithContext(ctx context.Context, databaseID string, throughput *ThroughputProperties) (*DatabaseClient, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	cDatabaseID := C.CString(databaseID)
+	defer C.free(unsafe.Pointer(cDatabaseID))
+
+	var database *C.struct_cosmos_database_client
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_client_create_database(c.client, cDatabaseID, throughput.toNative(), token, &database, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DatabaseClient{database: database, handle: trackHandle(handleDatabase)}
+
+	// Set finalizer to ensure cleanup
+	runtime.SetFinalizer(d, (*DatabaseClient).finalize)
+
+	return d, nil
+}
+
+// ListDatabases returns the properties of every database in the account
+func (c *CosmosClient) ListDatabases() ([]DatabaseProperties, error) {
+	return c.ListDatabasesWithContext(context.Background())
+}
+
+// ListDatabasesWithContext is like ListDatabases, but cancels the native request when ctx is done
+func (c *CosmosClient) ListDatabasesWithContext(ctx context.Context) ([]DatabaseProperties, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("client is closed")
+	}
+
+	var outJson *C.char
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_client_list_databases(c.client, token, &outJson, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		receivedString(outJson)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var databases []DatabaseProperties
+	if err := decodeNativeJson(outJson, &databases); err != nil {
+		return nil, fmt.Errorf("failed to decode database list: %w", err)
+	}
+
+	return databases, nil
+}
+
+// Read returns the properties of the database
+func (d *DatabaseClient) Read() (*DatabaseProperties, error) {
+	return d.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is like Read, but cancels the native request when ctx is done
+func (d *DatabaseClient) ReadWithContext(ctx context.Context) (*DatabaseProperties, error) {
+	if d.database == nil {
+		return nil, fmt.Errorf("database client is closed")
+	}
+
+	var outJson *C.char
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_database_read(d.database, token, &outJson, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		receivedString(outJson)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var properties DatabaseProperties
+	if err := decodeNativeJson(outJson, &properties); err != nil {
+		return nil, fmt.Errorf("failed to decode database properties: %w", err)
+	}
+
+	return &properties, nil
+}
+
+// Delete deletes the database and every container in it
+func (d *DatabaseClient) Delete() error {
+	return d.DeleteWithContext(context.Background())
+}
+
+// DeleteWithContext is like Delete, but cancels the native request when ctx is done
+func (d *DatabaseClient) DeleteWithContext(ctx context.Context) error {
+	if d.database == nil {
+		return fmt.Errorf("database client is closed")
+	}
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_database_delete(d.database, token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
+
+// CreateContainer creates a container in the database and returns a ContainerClient for it.
+// Pass nil throughput to use the database's shared throughput, or on serverless accounts.
+func (d *DatabaseClient) CreateContainer(properties ContainerProperties, throughput *ThroughputProperties) (*ContainerClient, error) {
+	return d.CreateContainerWithContext(context.Background(), properties, throughput)
+}
+
+// CreateContainerWithContext is like CreateContainer, but cancels the native request when ctx is done
+func (d *DatabaseClient) CreateContainerWithContext(ctx context.Context, properties ContainerProperties, throughput *ThroughputProperties) (*ContainerClient, error) {
+	if d.database == nil {
+		return nil, fmt.Errorf("database client is closed")
+	}
+
+	propertiesJson, err := json.Marshal(properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode container properties: %w", err)
+	}
+
+	cProperties := C.CString(string(propertiesJson))
+	defer C.free(unsafe.Pointer(cProperties))
+
+	var container *C.struct_cosmos_container_client
+
+	err = callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_database_create_container(d.database, cProperties, throughput.toNative(), token, &container, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ContainerClient{container: container, handle: trackHandle(handleContainer)}
+
+	// Set finalizer to ensure cleanup
+	runtime.SetFinalizer(c, (*ContainerClient).finalize)
+
+	return c, nil
+}
+
+// ListContainers returns the properties of every container in the database
+func (d *DatabaseClient) ListContainers() ([]ContainerProperties, error) {
+	return d.ListContainersWithContext(context.Background())
+}
+
+// ListContainersWithContext is like ListContainers, but cancels the native request when ctx is done
+func (d *DatabaseClient) ListContainersWithContext(ctx context.Context) ([]ContainerProperties, error) {
+	if d.database == nil {
+		return nil, fmt.Errorf("database client is closed")
+	}
+
+	var outJson *C.char
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_database_list_containers(d.database, token, &outJson, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		receivedString(outJson)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []ContainerProperties
+	if err := decodeNativeJson(outJson, &containers); err != nil {
+		return nil, fmt.Errorf("failed to decode container list: %w", err)
+	}
+
+	return containers, nil
+}
+
+// ReadThroughput returns the shared throughput provisioned on the database
+func (d *DatabaseClient) ReadThroughput() (*ThroughputProperties, error) {
+	return d.ReadThroughputWithContext(context.Background())
+}
+
+// ReadThroughputWithContext is like ReadThroughput, but cancels the native request when ctx is done
+func (d *DatabaseClient) ReadThroughputWithContext(ctx context.Context) (*ThroughputProperties, error) {
+	if d.database == nil {
+		return nil, fmt.Errorf("database client is closed")
+	}
+
+	var out C.struct_cosmos_throughput
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_database_read_throughput(d.database, token, &out, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ThroughputProperties{Throughput: int32(out.throughput), Autoscale: bool(out.autoscale)}, nil
+}
+
+// ReplaceThroughput changes the shared throughput provisioned on the database
+func (d *DatabaseClient) ReplaceThroughput(throughput ThroughputProperties) error {
+	return d.ReplaceThroughputWithContext(context.Background(), throughput)
+}
+
+// ReplaceThroughputWithContext is like ReplaceThroughput, but cancels the native request when ctx is done
+func (d *DatabaseClient) ReplaceThroughputWithContext(ctx context.Context, throughput ThroughputProperties) error {
+	if d.database == nil {
+		return fmt.Errorf("database client is closed")
+	}
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_database_replace_throughput(d.database, throughput.toNative(), token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
+
+// Read returns the properties of the container
+func (c *ContainerClient) Read() (*ContainerProperties, error) {
+	return c.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is like Read, but cancels the native request when ctx is done
+func (c *ContainerClient) ReadWithContext(ctx context.Context) (*ContainerProperties, error) {
+	if c.container == nil {
+		return nil, fmt.Errorf("container client is closed")
+	}
+
+	var outJson *C.char
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_read(c.container, token, &outJson, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		receivedString(outJson)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var properties ContainerProperties
+	if err := decodeNativeJson(outJson, &properties); err != nil {
+		return nil, fmt.Errorf("failed to decode container properties: %w", err)
+	}
+
+	return &properties, nil
+}
+
+// Delete deletes the container and every item in it
+func (c *ContainerClient) Delete() error {
+	return c.DeleteWithContext(context.Background())
+}
+
+// DeleteWithContext is like Delete, but cancels the native request when ctx is done
+func (c *ContainerClient) DeleteWithContext(ctx context.Context) error {
+	if c.container == nil {
+		return fmt.Errorf("container client is closed")
+	}
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_delete(c.container, token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
+
+// ReadThroughput returns the dedicated throughput provisioned on the container
+func (c *ContainerClient) ReadThroughput() (*ThroughputProperties, error) {
+	return c.ReadThroughputWithContext(context.Background())
+}
+
+// ReadThroughputWithContext is like ReadThroughput, but cancels the native request when ctx is done
+func (c *ContainerClient) ReadThroughputWithContext(ctx context.Context) (*ThroughputProperties, error) {
+	if c.container == nil {
+		return nil, fmt.Errorf("container client is closed")
+	}
+
+	var out C.struct_cosmos_throughput
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_read_throughput(c.container, token, &out, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ThroughputProperties{Throughput: int32(out.throughput), Autoscale: bool(out.autoscale)}, nil
+}
+
+// ReplaceThroughput changes the dedicated throughput provisioned on the container
+func (c *ContainerClient) ReplaceThroughput(throughput ThroughputProperties) error {
+	return c.ReplaceThroughputWithContext(context.Background(), throughput)
+}
+
+// ReplaceThroughputWithContext is like ReplaceThroughput, but cancels the native request when ctx is done
+func (c *ContainerClient) ReplaceThroughputWithContext(ctx context.Context, throughput ThroughputProperties) error {
+	if c.container == nil {
+		return fmt.Errorf("container client is closed")
+	}
+
+	return callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_container_replace_throughput(c.container, throughput.toNative(), token, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,200 @@
+package azurecosmos
+
+/*
+#include <stdlib.h>
+#include "azurecosmos.h"
+*/
+import "C"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"math"
+	"runtime"
+	"unsafe"
+)
+
+// QueryParameter is a named parameter referenced by a query, e.g. `@id`
+type QueryParameter struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// QueryOptions controls how query results are paged
+type QueryOptions struct {
+	// MaxItemCount limits the number of items returned per page, up to math.MaxInt32. Zero uses the
+	// service default.
+	MaxItemCount int
+	// ContinuationToken resumes a query from the page after the one that returned the token
+	ContinuationToken string
+}
+
+// QueryPage is a single page of query results
+type QueryPage struct {
+	// Items holds the JSON of each item in the page
+	Items []json.RawMessage
+	// ContinuationToken can be passed in QueryOptions to resume the query after this page.
+	// It is empty on the last page.
+	ContinuationToken string
+}
+
+// QueryPager wraps the native cosmos_query_pager pointer and fetches query results one page at a time.
+// It is not safe for concurrent use.
+type QueryPager struct {
+	pager  *C.struct_cosmos_query_pager
+	done   bool
+	handle *trackedHandle
+}
+
+// QueryItems starts a SQL query against the container. If partitionKey is empty, the query fans out
+// across all partitions. No request is sent until the first page is fetched.
+// The returned pager must be closed, or fully consumed through Items, to release native resources.
+func (c *ContainerClient) QueryItems(query string, params []QueryParameter, partitionKey string, opts *QueryOptions) (*QueryPager, error) {
+	if c.container == nil {
+		return nil, fmt.Errorf("container client is closed")
+	}
+
+	if opts == nil {
+		opts = &QueryOptions{}
+	}
+	if opts.MaxItemCount < 0 || opts.MaxItemCount > math.MaxInt32 {
+		return nil, fmt.Errorf("MaxItemCount %d is out of range, expected 0 to %d", opts.MaxItemCount, math.MaxInt32)
+	}
+
+	cQuery := C.CString(query)
+	defer C.free(unsafe.Pointer(cQuery))
+
+	var cParams *C.char
+	if len(params) > 0 {
+		paramsJson, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode query parameters: %w", err)
+		}
+		cParams = C.CString(string(paramsJson))
+		defer C.free(unsafe.Pointer(cParams))
+	}
+
+	var cPartitionKey *C.char
+	if partitionKey != "" {
+		cPartitionKey = C.CString(partitionKey)
+		defer C.free(unsafe.Pointer(cPartitionKey))
+	}
+
+	var cContinuation *C.char
+	if opts.ContinuationToken != "" {
+		cContinuation = C.CString(opts.ContinuationToken)
+		defer C.free(unsafe.Pointer(cContinuation))
+	}
+
+	var pager *C.struct_cosmos_query_pager
+	var cerr C.struct_cosmos_error
+
+	code := C.cosmos_container_query_items(c.container, cQuery, cParams, cPartitionKey, C.int32_t(opts.MaxItemCount), cContinuation, &pager, &cerr)
+
+	if code != C.COSMOS_ERROR_CODE_SUCCESS {
+		return nil, newCosmosError(cerr)
+	}
+
+	p := &QueryPager{pager: pager, handle: trackHandle(handleQueryPager)}
+
+	// Set finalizer to ensure cleanup
+	runtime.SetFinalizer(p, (*QueryPager).finalize)
+
+	return p, nil
+}
+
+// finalize cleans up the native query pager if it was never closed
+func (p *QueryPager) finalize() {
+	p.release(false)
+}
+
+// Close explicitly releases the native query pager resources
+func (p *QueryPager) Close() {
+	runtime.SetFinalizer(p, nil)
+	p.release(true)
+}
+
+func (p *QueryPager) release(closed bool) {
+	if p.pager != nil {
+		C.cosmos_query_pager_free(p.pager)
+		p.pager = nil
+		releaseHandle(handleQueryPager, p.handle, closed)
+	}
+}
+
+// More reports whether there are more pages to fetch
+func (p *QueryPager) More() bool {
+	return !p.done && p.pager != nil
+}
+
+// NextPage fetches the next page of results
+func (p *QueryPager) NextPage(ctx context.Context) (*QueryPage, error) {
+	if p.pager == nil {
+		return nil, fmt.Errorf("query pager is closed")
+	}
+	if p.done {
+		return nil, fmt.Errorf("query pager has no more pages")
+	}
+
+	var outItems *C.uint8_t
+	var outLen C.size_t
+	var outContinuation *C.char
+	var outHasMore C.bool
+
+	err := callWithContext(ctx, func(token *C.struct_cosmos_cancellation_token) error {
+		var cerr C.struct_cosmos_error
+
+		code := C.cosmos_query_pager_next_page(p.pager, token, &outItems, &outLen, &outContinuation, &outHasMore, &cerr)
+
+		if code != C.COSMOS_ERROR_CODE_SUCCESS {
+			return newCosmosError(cerr)
+		}
+		receivedString(outContinuation)
+		receivedBytes(outItems, outLen)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &QueryPage{}
+	p.done = !bool(outHasMore)
+
+	if outContinuation != nil {
+		page.ContinuationToken = nativeString(outContinuation)
+	}
+
+	if outItems != nil {
+		// The native layer returns the page's items as a single JSON array
+		err = json.Unmarshal(nativeBytes(outItems, outLen), &page.Items)
+		freeNativeBytes(outItems, outLen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode query page: %w", err)
+		}
+	}
+
+	return page, nil
+}
+
+// Items iterates over every item in the remaining pages, fetching pages as needed.
+// The pager is closed when iteration finishes, fails, or is stopped early.
+func (p *QueryPager) Items(ctx context.Context) iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		defer p.Close()
+
+		for p.More() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
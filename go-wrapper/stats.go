@@ -0,0 +1,156 @@
+package azurecosmos
+
+/*
+#include <string.h>
+#include "azurecosmos.h"
+*/
+import "C"
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// NativeStats counts the native resources held through the wrapper: handles it has created and not
+// yet freed, and strings and byte buffers the native library has returned that the wrapper has not
+// yet freed. Go's memory statistics can't see any of these, so a handle that is never closed or a
+// buffer that is never freed only shows up here. Buffers are counted as soon as the native call
+// returns them, so one that is dropped without being freed stays counted. The native library doesn't
+// report its own allocations, so memory it uses internally, such as connection pools, only shows up
+// in the process RSS.
+type NativeStats struct {
+	// Live handles by type. Handles are counted until they are closed or finalized.
+	Clients            int64
+	Databases          int64
+	Containers         int64
+	QueryPagers        int64
+	CancellationTokens int64
+
+	// Buffers and BufferBytes are the native strings and byte buffers not yet freed, and their size
+	Buffers     int64
+	BufferBytes int64
+
+	// TotalBuffers and TotalBufferBytes count every buffer received since the process started
+	TotalBuffers     int64
+	TotalBufferBytes int64
+}
+
+// LiveHandles returns the number of live handles of every type
+func (s NativeStats) LiveHandles() int64 {
+	return s.Clients + s.Databases + s.Containers + s.QueryPagers + s.CancellationTokens
+}
+
+// handleKind identifies a type of native handle
+type handleKind int
+
+const (
+	handleClient handleKind = iota
+	handleDatabase
+	handleContainer
+	handleQueryPager
+	handleCancellationToken
+	handleKindCount
+)
+
+var (
+	liveHandles      [handleKindCount]atomic.Int64
+	liveBuffers      atomic.Int64
+	liveBufferBytes  atomic.Int64
+	totalBuffers     atomic.Int64
+	totalBufferBytes atomic.Int64
+)
+
+// Stats returns the current native resource counts
+func Stats() NativeStats {
+	return NativeStats{
+		Clients:            liveHandles[handleClient].Load(),
+		Databases:          liveHandles[handleDatabase].Load(),
+		Containers:         liveHandles[handleContainer].Load(),
+		QueryPagers:        liveHandles[handleQueryPager].Load(),
+		CancellationTokens: liveHandles[handleCancellationToken].Load(),
+		Buffers:            liveBuffers.Load(),
+		BufferBytes:        liveBufferBytes.Load(),
+		TotalBuffers:       totalBuffers.Load(),
+		TotalBufferBytes:   totalBufferBytes.Load(),
+	}
+}
+
+// trackHandle counts a handle returned by the native library. If leak detection is enabled it also
+// records where the handle was created, and the returned record must be passed to releaseHandle.
+func trackHandle(kind handleKind) *trackedHandle {
+	liveHandles[kind].Add(1)
+	if !leakCheck.Load() {
+		return nil
+	}
+	return recordHandle(kind)
+}
+
+// countHandle counts a short-lived handle that is freed before the call that created it returns.
+// Unlike trackHandle it never records a stack, so leak detection adds nothing to the request path.
+func countHandle(kind handleKind) {
+	liveHandles[kind].Add(1)
+}
+
+// releaseHandle counts a handle as freed, either by Close or by a finalizer. handle is nil for
+// handles counted with countHandle, or created while leak detection was disabled.
+func releaseHandle(kind handleKind, handle *trackedHandle, closed bool) {
+	liveHandles[kind].Add(-1)
+	if handle != nil {
+		forgetHandle(handle, closed)
+	}
+}
+
+// trackBuffer counts a buffer of size bytes returned by the native library
+func trackBuffer(size int) {
+	liveBuffers.Add(1)
+	liveBufferBytes.Add(int64(size))
+	totalBuffers.Add(1)
+	totalBufferBytes.Add(int64(size))
+}
+
+// releaseBuffer counts a buffer of size bytes as freed
+func releaseBuffer(size int) {
+	liveBuffers.Add(-1)
+	liveBufferBytes.Add(-int64(size))
+}
+
+// receivedString counts a string returned by a native call. Every string counted must be freed with
+// nativeString or freeNativeString.
+func receivedString(s *C.char) {
+	if s != nil {
+		trackBuffer(int(C.strlen(s)) + 1)
+	}
+}
+
+// receivedBytes counts a byte buffer returned by a native call. Every buffer counted must be freed
+// with freeNativeBytes.
+func receivedBytes(data *C.uint8_t, n C.size_t) {
+	if data != nil {
+		trackBuffer(int(n))
+	}
+}
+
+// nativeString copies a string counted by receivedString into Go memory and frees it
+func nativeString(s *C.char) string {
+	result := C.GoString(s)
+	freeNativeString(s)
+	return result
+}
+
+// freeNativeString frees a string counted by receivedString
+func freeNativeString(s *C.char) {
+	size := int(C.strlen(s)) + 1
+	C.cosmos_string_free(s)
+	releaseBuffer(size)
+}
+
+// nativeBytes returns a view of a byte buffer counted by receivedBytes. The view must not be used
+// after the buffer is freed with freeNativeBytes.
+func nativeBytes(data *C.uint8_t, n C.size_t) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(data)), int(n))
+}
+
+// freeNativeBytes frees a buffer counted by receivedBytes
+func freeNativeBytes(data *C.uint8_t, n C.size_t) {
+	C.cosmos_bytes_free(data, n)
+	releaseBuffer(int(n))
+}
@@ -0,0 +1,84 @@
+package azurecosmos
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// ReadItemAs reads an item by ID and partition key and decodes it into a T using the container's codec
+func ReadItemAs[T any](ctx context.Context, c *ContainerClient, itemID, partitionKey string) (T, error) {
+	var item T
+
+	data, err := c.ReadItemBytesWithContext(ctx, itemID, partitionKey)
+	if err != nil {
+		return item, err
+	}
+
+	if err := c.Codec().Unmarshal(data, &item); err != nil {
+		return item, fmt.Errorf("failed to decode item %s: %w", itemID, err)
+	}
+
+	return item, nil
+}
+
+// CreateItemFrom encodes item using the container's codec and creates it in the container
+func CreateItemFrom[T any](ctx context.Context, c *ContainerClient, partitionKey string, item T) error {
+	data, err := c.Codec().Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode item: %w", err)
+	}
+
+	return c.CreateItemWithContext(ctx, partitionKey, string(data))
+}
+
+// UpsertItemFrom encodes item using the container's codec and upserts it into the container
+func UpsertItemFrom[T any](ctx context.Context, c *ContainerClient, partitionKey string, item T) error {
+	data, err := c.Codec().Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode item: %w", err)
+	}
+
+	return c.UpsertItemWithContext(ctx, partitionKey, string(data))
+}
+
+// ReplaceItemFrom encodes item using the container's codec and replaces the existing item with it
+func ReplaceItemFrom[T any](ctx context.Context, c *ContainerClient, itemID, partitionKey string, item T) error {
+	data, err := c.Codec().Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode item %s: %w", itemID, err)
+	}
+
+	return c.ReplaceItemWithContext(ctx, itemID, partitionKey, string(data))
+}
+
+// QueryItemsAs runs a query and decodes each result into a T using the container's codec.
+// The underlying pager is released when iteration finishes or is stopped early.
+func QueryItemsAs[T any](ctx context.Context, c *ContainerClient, query string, params []QueryParameter, partitionKey string, opts *QueryOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		pager, err := c.QueryItems(query, params, partitionKey, opts)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for data, err := range pager.Items(ctx) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var item T
+			if err := c.Codec().Unmarshal(data, &item); err != nil {
+				yield(zero, fmt.Errorf("failed to decode query result: %w", err))
+				return
+			}
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
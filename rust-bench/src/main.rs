@@ -58,6 +58,10 @@ enum Commands {
         /// Number of concurrent workers
         #[arg(short = 'w', long = "workers", default_value_t = num_cpus::get())]
         workers: usize,
+
+        /// Also write the results to this file as JSON, for the Go benchmarks' compare command
+        #[arg(long = "output-file")]
+        output_file: Option<String>,
     },
 }
 
@@ -89,8 +93,9 @@ async fn main() -> Result<()> {
             duration_seconds,
             partition_count,
             workers,
+            output_file,
         } => {
-            run_point_read_benchmark(
+            let results = run_point_read_benchmark(
                 &cli.endpoint,
                 &cli.key,
                 &cli.database,
@@ -100,6 +105,10 @@ async fn main() -> Result<()> {
                 workers,
             )
             .await?;
+
+            if let Some(path) = output_file {
+                std::fs::write(path, serde_json::to_string_pretty(&results)?)?;
+            }
         }
     }
 
@@ -114,7 +123,7 @@ async fn run_point_read_benchmark(
     duration_seconds: u64,
     partition_count: i32,
     workers: usize,
-) -> Result<()> {
+) -> Result<BenchmarkResults> {
     // Create Cosmos client
     let credential = Secret::from(key.to_string());
     let client = CosmosClient::with_key(endpoint, credential, None)?;
@@ -141,7 +150,7 @@ async fn run_point_read_benchmark(
     // Print results
     print_results(&results);
 
-    Ok(())
+    Ok(results)
 }
 
 async fn execute_benchmark(